@@ -0,0 +1,77 @@
+// Command properties-merge merges property files key by key, for use as a
+// git merge driver:
+//
+//	properties-merge [-ours-label name] [-theirs-label name] base current other
+//
+// The merge of current and other, both derived from base, is written to
+// current. Conflict markers are written only around the keys changed
+// differently on both sides. The exit status is 0 for a clean merge,
+// 1 if there are conflicts and 2 on error.
+//
+// To merge the .properties files of a repository with it, configure git:
+//
+//	git config merge.properties.name "key-aware properties merge"
+//	git config merge.properties.driver "properties-merge %O %A %B"
+//
+// and add to .gitattributes:
+//
+//	*.properties merge=properties
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/zooyer/properties"
+)
+
+func main() {
+	var oursLabel = flag.String("ours-label", "ours", "label of the current side in conflict markers")
+	var theirsLabel = flag.String("theirs-label", "theirs", "label of the other side in conflict markers")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: properties-merge [flags] base current other")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 3 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	conflicts, err := merge(flag.Arg(0), flag.Arg(1), flag.Arg(2), *oursLabel, *theirsLabel)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "properties-merge:", err)
+		os.Exit(2)
+	}
+	if len(conflicts) > 0 {
+		for _, key := range conflicts {
+			fmt.Fprintf(os.Stderr, "properties-merge: conflict in %s: %s\n", flag.Arg(1), key)
+		}
+		os.Exit(1)
+	}
+}
+
+func merge(base, current, other, oursLabel, theirsLabel string) ([]string, error) {
+	var files [3]*os.File
+	for i, name := range []string{base, current, other} {
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		files[i] = file
+	}
+
+	var out bytes.Buffer
+	conflicts, err := properties.Merge3(&out, files[0], files[1], files[2], oursLabel, theirsLabel)
+	if err != nil {
+		return nil, err
+	}
+	if err = files[1].Close(); err != nil {
+		return nil, err
+	}
+
+	return conflicts, os.WriteFile(current, out.Bytes(), 0666)
+}
@@ -0,0 +1,109 @@
+package properties
+
+import (
+	"errors"
+	"sync"
+)
+
+// Returned by SetDefaults if the property list would become its own default.
+var ErrDefaultsCycle = errors.New("properties: defaults cycle")
+
+// Serializes SetDefaults, so concurrent calls cannot create a cycle together.
+var defaultsMutex sync.Mutex
+
+// Returns the default property lists in search order.
+func (p *Properties) Defaults() []*Properties {
+	return append([]*Properties(nil), p.parents()...)
+}
+
+// Replaces the default property lists, searched in order. Nil defaults are
+// ignored. Returns ErrDefaultsCycle, and changes nothing, if p is reachable
+// from any of the defaults, which would make GetProperty recurse forever.
+// Returns ErrFrozen if p is frozen.
+func (p *Properties) SetDefaults(defaults ...*Properties) error {
+	defaultsMutex.Lock()
+	defer defaultsMutex.Unlock()
+
+	if err := p.writable(); err != nil {
+		return err
+	}
+
+	var visited = make(map[*Properties]bool)
+	for _, d := range defaults {
+		if d != nil && d.reaches(p, visited) {
+			return ErrDefaultsCycle
+		}
+	}
+	p.setDefaults(defaults)
+
+	return nil
+}
+
+func (p *Properties) setDefaults(defaults []*Properties) {
+	var list = make([]*Properties, 0, len(defaults))
+	for _, d := range defaults {
+		if d != nil {
+			list = append(list, d)
+		}
+	}
+	p.defaults.Store(&list)
+}
+
+// Returns the default property lists, the slice must not be modified.
+func (p *Properties) parents() []*Properties {
+	if defaults := p.defaults.Load(); defaults != nil {
+		return *defaults
+	}
+
+	return nil
+}
+
+// Reports whether target is p or one of its defaults, recursively.
+func (p *Properties) reaches(target *Properties, visited map[*Properties]bool) bool {
+	if p == target {
+		return true
+	}
+	if visited[p] {
+		return false
+	}
+	visited[p] = true
+	for _, d := range p.parents() {
+		if d.reaches(target, visited) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Searches for the property in the default property lists, in order.
+func (p *Properties) getDefault(key string) (string, bool) {
+	for _, d := range p.parents() {
+		if val, exist := d.GetProperty(key); exist {
+			return val, true
+		}
+	}
+
+	return "", false
+}
+
+// Reports whether the value of the key comes from a default property list,
+// that is the key is not in this property list but in one of its defaults.
+func (p *Properties) IsDefault(key string) bool {
+	if _, ok := p.Get(key).(string); ok {
+		return false
+	}
+	_, exist := p.getDefault(key)
+
+	return exist
+}
+
+// Creates a standalone property list, of the same type as this one and
+// without defaults, holding the effective properties of this property list
+// and its defaults.
+func (p *Properties) Flatten() *Properties {
+	var flat = p.New()
+	p.enumerate(flat.Hashtable)
+
+	return flat
+}
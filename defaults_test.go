@@ -0,0 +1,89 @@
+package properties
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"sort"
+	"testing"
+)
+
+func TestProperties_SetDefaults(t *testing.T) {
+	common := NewProperties()
+	common.SetProperty("a", "common")
+	common.SetProperty("b", "common")
+	common.SetProperty("c", "common")
+	region := NewPropertiesDefault(common)
+	region.SetProperty("b", "region")
+	team := NewProperties()
+	team.SetProperty("a", "team")
+
+	p := NewPropertiesDefault(nil)
+	diff := cmp.Diff(len(p.Defaults()), 0)
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	if err := p.SetDefaults(team, nil, region); err != nil {
+		t.Fatal(err)
+	}
+	if d := p.Defaults(); len(d) != 2 || d[0] != team || d[1] != region {
+		t.Fatal("defaults:", d)
+	}
+	p.SetProperty("d", "p")
+
+	tests := map[string]string{"a": "team", "b": "region", "c": "common", "d": "p"}
+	for key, val := range tests {
+		diff = cmp.Diff(p.GetPropertyByDefault(key, ""), val)
+		if diff != "" {
+			t.Fatal(key, diff)
+		}
+	}
+	diff = cmp.Diff([]bool{p.IsDefault("a"), p.IsDefault("d"), p.IsDefault("missing")}, []bool{true, false, false})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	flat := p.Flatten()
+	diff = cmp.Diff([]interface{}{flat.ToMap(), len(flat.Defaults())}, []interface{}{map[interface{}]interface{}{"a": "team", "b": "region", "c": "common", "d": "p"}, 0})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(p.Snapshot().ToMap(), map[string]string{"a": "team", "b": "region", "c": "common", "d": "p"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	effective := map[string]string{}
+	for key, value := range p.Effective() {
+		if _, ok := effective[key]; ok {
+			t.Fatal("duplicate key", key)
+		}
+		effective[key] = value
+	}
+	diff = cmp.Diff(effective, map[string]string{"a": "team", "b": "region", "c": "common", "d": "p"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	names := p.StringPropertyNames()
+	sort.Strings(names)
+	diff = cmp.Diff(names, []string{"a", "b", "c", "d"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	if err := common.SetDefaults(p); err != ErrDefaultsCycle {
+		t.Fatal("cycle not detected:", err)
+	}
+	if err := p.SetDefaults(p); err != ErrDefaultsCycle {
+		t.Fatal("cycle not detected:", err)
+	}
+	diff = cmp.Diff(len(common.Defaults()), 0)
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	if err := p.SetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff(p.GetPropertyByDefault("a", "none"), "none")
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,182 @@
+package properties
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Returns the changes that turn the string properties of a into those of b,
+// sorted by key, not including the default property lists.
+// Each property list is read at one point in time.
+func Diff(a, b *Properties) []Change {
+	return diffMaps(a.Snapshot().values, b.Snapshot().values)
+}
+
+// Like Diff, comparing the effective properties of a and b,
+// including their default property lists.
+func DiffEffective(a, b *Properties) []Change {
+	return diffMaps(a.Snapshot().ToMap(), b.Snapshot().ToMap())
+}
+
+func diffMaps(a, b map[string]string) []Change {
+	var changes []Change
+	for key, old := range a {
+		if val, exist := b[key]; !exist {
+			changes = append(changes, Change{Type: Removed, Key: key, Old: old})
+		} else if val != old {
+			changes = append(changes, Change{Type: Updated, Key: key, Old: old, New: val})
+		}
+	}
+	for key, val := range b {
+		if _, exist := a[key]; !exist {
+			changes = append(changes, Change{Type: Added, Key: key, New: val})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes
+}
+
+// Writes the changes one per line for people to read:
+//
+//	+ key = value
+//	- key = value
+//	~ key = old -> new
+//
+// Keys and values are escaped as by Store, non-ASCII characters are not.
+func WriteDiff(w io.Writer, changes []Change) error {
+	var bw = bufio.NewWriter(w)
+	for _, c := range changes {
+		var line string
+		switch c.Type {
+		case Added:
+			line = "+ " + formatEntry(c.Key, c.New)
+		case Removed:
+			line = "- " + formatEntry(c.Key, c.Old)
+		default:
+			line = "~ " + formatEntry(c.Key, c.Old) + " -> " + formatValue(c.New)
+		}
+		if _, err := bw.WriteString(line); err != nil {
+			return err
+		}
+		if _, err := bw.Write(newLine()); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// Writes the changes in the style of a unified diff of the stored property
+// lists, without context lines: the file names fromName and toName, then a
+// "-" line with the old entry and a "+" line with the new entry of each
+// change. Nothing is written if there are no changes, like diff -u.
+func WriteUnifiedDiff(w io.Writer, changes []Change, fromName, toName string) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	var lines = []string{"--- " + fromName, "+++ " + toName}
+	for _, c := range changes {
+		if c.Type != Added {
+			lines = append(lines, "-"+formatEntry(c.Key, c.Old))
+		}
+		if c.Type != Removed {
+			lines = append(lines, "+"+formatEntry(c.Key, c.New))
+		}
+	}
+
+	var bw = bufio.NewWriter(w)
+	for _, line := range lines {
+		if _, err := bw.WriteString(line); err != nil {
+			return err
+		}
+		if _, err := bw.Write(newLine()); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// Formats a property as a line of Store.
+func formatEntry(key, value string) string {
+	return saveConvert(key, true, false) + " = " + formatValue(value)
+}
+
+func formatValue(value string) string {
+	return saveConvert(value, false, false)
+}
+
+// Resolution of a key that has different values in the destination and
+// the source of Merge.
+type MergePolicy int
+
+const (
+	// The value of the source replaces the value of the destination.
+	MergeOverwrite MergePolicy = iota
+	// The value of the destination is kept.
+	MergeKeepExisting
+	// Nothing is merged and a *MergeConflictError is returned.
+	MergeErrorOnConflict
+)
+
+// Reports the keys that have different values in the destination and the
+// source of Merge with MergeErrorOnConflict.
+type MergeConflictError struct {
+	// The conflicting keys with the value of the destination as Old and
+	// the value of the source as New, sorted by key.
+	Conflicts []Change
+}
+
+func (e *MergeConflictError) Error() string {
+	var keys = make([]string, len(e.Conflicts))
+	for i, c := range e.Conflicts {
+		keys[i] = c.Key
+	}
+
+	return "properties: merge conflict on keys: " + strings.Join(keys, ", ")
+}
+
+// Copies the string properties of src, not including its default property
+// list, into dst atomically, resolving the keys that dst has with another
+// value by the policy. Returns the changes made to dst.
+func Merge(dst, src *Properties, policy MergePolicy) ([]Change, error) {
+	var values = src.Snapshot().values
+	var keys = make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	dst.mutex.Lock()
+	defer dst.mutex.Unlock()
+
+	if err := dst.writableLocked(); err != nil {
+		return nil, err
+	}
+	var conflicts []Change
+	for _, key := range keys {
+		if old := dst.Hashtable.Get(key); old != nil {
+			conflicts = appendChange(conflicts, key, old, values[key])
+		}
+	}
+	if len(conflicts) > 0 && policy == MergeErrorOnConflict {
+		return nil, &MergeConflictError{Conflicts: conflicts}
+	}
+
+	var changes []Change
+	for _, key := range keys {
+		if policy == MergeKeepExisting && dst.Hashtable.ContainsKey(key) {
+			continue
+		}
+		changes = appendChange(changes, key, dst.Hashtable.Put(key, values[key]), values[key])
+	}
+	dst.commitLocked(changes)
+
+	return changes, nil
+}
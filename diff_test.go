@@ -0,0 +1,107 @@
+package properties
+
+import (
+	"bytes"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	defaults := NewProperties()
+	defaults.SetProperty("timeout", "30")
+	staging := NewPropertiesDefault(defaults)
+	staging.SetProperty("db.host", "staging")
+	staging.SetProperty("debug", "true")
+	staging.SetProperty("name", "app")
+	production := NewProperties()
+	production.SetProperty("db.host", "prod")
+	production.SetProperty("name", "app")
+	production.SetProperty("replicas", "3")
+
+	changes := Diff(staging, production)
+	diff := cmp.Diff(changes, []Change{
+		{Updated, "db.host", "staging", "prod"},
+		{Removed, "debug", "true", ""},
+		{Added, "replicas", "", "3"},
+	})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(DiffEffective(staging, production)[3], Change{Removed, "timeout", "30", ""})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDiff(&buf, changes); err != nil {
+		t.Fatal(err)
+	}
+	nl := string(newLine())
+	diff = cmp.Diff(buf.String(), "~ db.host = staging -> prod"+nl+"- debug = true"+nl+"+ replicas = 3"+nl)
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	buf.Reset()
+	if err := WriteUnifiedDiff(&buf, changes, "staging", "production"); err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff(buf.String(), "--- staging"+nl+"+++ production"+nl+"-db.host = staging"+nl+"+db.host = prod"+nl+"-debug = true"+nl+"+replicas = 3"+nl)
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	buf.Reset()
+	if err := WriteUnifiedDiff(&buf, nil, "a", "b"); err != nil || buf.Len() != 0 {
+		t.Fatal(err, buf.String())
+	}
+}
+
+func TestMerge(t *testing.T) {
+	newDst := func() *Properties {
+		p := NewProperties()
+		p.SetProperty("a", "dst")
+		p.SetProperty("b", "same")
+		return p
+	}
+	src := NewProperties()
+	src.SetProperty("a", "src")
+	src.SetProperty("b", "same")
+	src.SetProperty("c", "src")
+
+	dst := newDst()
+	changes, err := Merge(dst, src, MergeOverwrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := cmp.Diff([]interface{}{changes, dst.ToMap()}, []interface{}{
+		[]Change{{Updated, "a", "dst", "src"}, {Added, "c", "", "src"}},
+		map[interface{}]interface{}{"a": "src", "b": "same", "c": "src"},
+	})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	dst = newDst()
+	if _, err = Merge(dst, src, MergeKeepExisting); err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff(dst.ToMap(), map[interface{}]interface{}{"a": "dst", "b": "same", "c": "src"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	dst = newDst()
+	_, err = Merge(dst, src, MergeErrorOnConflict)
+	var conflict *MergeConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatal("conflict not reported:", err)
+	}
+	diff = cmp.Diff([]interface{}{conflict.Conflicts, err.Error(), dst.ToMap()}, []interface{}{
+		[]Change{{Updated, "a", "dst", "src"}},
+		"properties: merge conflict on keys: a",
+		map[interface{}]interface{}{"a": "dst", "b": "same"},
+	})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,137 @@
+package properties
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// Case rule applied when an environment variable name is mapped to a property key.
+type EnvCase int
+
+const (
+	// APP_DB_HOST is mapped to db.host, and db.host back to APP_DB_HOST.
+	LowerCase EnvCase = iota
+	// APP_DB_HOST is mapped to DB.HOST, and db.host back to APP_DB_HOST.
+	UpperCase
+	// Names and keys are mapped without changing case.
+	KeepCase
+)
+
+// Maps environment variables onto property keys and back.
+// The zero value maps every variable, lower cased, with "_" turned into ".".
+type EnvMapping struct {
+	// Only variables starting with Prefix are mapped, the prefix
+	// is stripped before the name is converted, e.g. "APP_".
+	Prefix string
+
+	// Word separator of variable names, "_" if empty.
+	Separator string
+
+	// Word separator of property keys, "." if empty.
+	Delimiter string
+
+	// Case rule of property keys.
+	Case EnvCase
+}
+
+var defaultEnvMapping EnvMapping
+
+func (m *EnvMapping) separator() string {
+	if m.Separator == "" {
+		return "_"
+	}
+	return m.Separator
+}
+
+func (m *EnvMapping) delimiter() string {
+	if m.Delimiter == "" {
+		return "."
+	}
+	return m.Delimiter
+}
+
+// Returns the property key of the environment variable name.
+// Return "", false if the name does not carry the prefix or is empty after it.
+func (m *EnvMapping) Key(name string) (string, bool) {
+	if !strings.HasPrefix(name, m.Prefix) {
+		return "", false
+	}
+	name = name[len(m.Prefix):]
+	if name == "" {
+		return "", false
+	}
+
+	var key = strings.Replace(name, m.separator(), m.delimiter(), -1)
+	switch m.Case {
+	case LowerCase:
+		key = strings.ToLower(key)
+	case UpperCase:
+		key = strings.ToUpper(key)
+	}
+
+	return key, true
+}
+
+// Returns the environment variable name of the property key.
+func (m *EnvMapping) Name(key string) string {
+	var name = strings.Replace(key, m.delimiter(), m.separator(), -1)
+	if m.Case != KeepCase {
+		name = strings.ToUpper(name)
+	}
+
+	return m.Prefix + name
+}
+
+// Overlays the variables of environ, in the "name=value" form of os.Environ,
+// onto this property list atomically. Variables not matched by the mapping
+// are ignored. A nil mapping is the zero EnvMapping.
+func (p *Properties) LoadEnviron(mapping *EnvMapping, environ []string) error {
+	if mapping == nil {
+		mapping = &defaultEnvMapping
+	}
+
+	var table = p.newHashtable()
+	for _, kv := range environ {
+		var i = strings.Index(kv, "=")
+		if i <= 0 {
+			continue
+		}
+		if key, ok := mapping.Key(kv[:i]); ok {
+			table.Put(key, kv[i+1:])
+		}
+	}
+
+	return p.merge(table)
+}
+
+// Call p.LoadEnviron(mapping, os.Environ()).
+func (p *Properties) LoadEnv(mapping *EnvMapping) error {
+	return p.LoadEnviron(mapping, os.Environ())
+}
+
+// Returns the properties, including the default property list, as
+// "name=value" variables sorted by name, suitable for exec.Cmd.Env.
+// A nil mapping is the zero EnvMapping.
+func (p *Properties) ToEnviron(mapping *EnvMapping) []string {
+	if mapping == nil {
+		mapping = &defaultEnvMapping
+	}
+
+	var keys = p.StringPropertyNames()
+	var names = make(map[string]string, len(keys))
+	for _, key := range keys {
+		names[key] = mapping.Name(key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return names[keys[i]] < names[keys[j]]
+	})
+
+	var environ = make([]string, 0, len(keys))
+	for _, key := range keys {
+		val, _ := p.GetProperty(key)
+		environ = append(environ, names[key]+"="+val)
+	}
+
+	return environ
+}
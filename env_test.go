@@ -0,0 +1,79 @@
+package properties
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestEnvMapping(t *testing.T) {
+	m := &EnvMapping{Prefix: "APP_"}
+	key, ok := m.Key("APP_DB_HOST")
+	diff := cmp.Diff([]interface{}{key, ok}, []interface{}{"db.host", true})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	if _, ok = m.Key("HOME"); ok {
+		t.Fatal("HOME mapped without prefix")
+	}
+	if _, ok = m.Key("APP_"); ok {
+		t.Fatal("empty name mapped")
+	}
+	diff = cmp.Diff(m.Name("db.host"), "APP_DB_HOST")
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	m = &EnvMapping{Separator: "__", Delimiter: "/", Case: KeepCase}
+	key, _ = m.Key("Db__Host")
+	diff = cmp.Diff(key, "Db/Host")
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(m.Name("Db/Host"), "Db__Host")
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestProperties_LoadEnviron(t *testing.T) {
+	defaults := NewProperties()
+	defaults.SetProperty("db.port", "5432")
+	p := NewPropertiesDefault(defaults)
+	p.SetProperty("db.host", "localhost")
+
+	m := &EnvMapping{Prefix: "APP_"}
+	if err := p.LoadEnviron(m, []string{"APP_DB_HOST=db.internal", "APP_LOG_LEVEL=debug", "HOME=/root", "APP_EMPTY="}); err != nil {
+		t.Fatal(err)
+	}
+	diff := cmp.Diff(p.GetPropertyByDefault("db.host", ""), "db.internal")
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(p.GetPropertyByDefault("log.level", ""), "debug")
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	if _, exist := p.GetProperty("home"); exist {
+		t.Fatal("unprefixed variable loaded")
+	}
+
+	diff = cmp.Diff(p.ToEnviron(m), []string{
+		"APP_DB_HOST=db.internal",
+		"APP_DB_PORT=5432",
+		"APP_EMPTY=",
+		"APP_LOG_LEVEL=debug",
+	})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	var version = p.Version()
+	p.Freeze()
+	if err := p.LoadEnviron(m, []string{"APP_DB_HOST=db.other", "APP_LOG_LEVEL=info"}); err != ErrFrozen {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff([]interface{}{p.Version(), p.GetPropertyByDefault("db.host", "")}, []interface{}{version, "db.internal"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
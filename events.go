@@ -0,0 +1,250 @@
+package properties
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Kind of change of a property.
+type ChangeType int
+
+const (
+	// The key was not in the property list before.
+	Added ChangeType = iota + 1
+	// The value of the key changed.
+	Updated
+	// The key was removed from the property list.
+	Removed
+)
+
+func (t ChangeType) String() string {
+	switch t {
+	case Added:
+		return "added"
+	case Updated:
+		return "updated"
+	case Removed:
+		return "removed"
+	}
+
+	return fmt.Sprintf("ChangeType(%d)", int(t))
+}
+
+// A change of one property. Keys and values that are not strings are
+// given in their ConvertValues form, or their fmt form if they have none.
+// Old is empty for Added, New is empty for Removed.
+type Change struct {
+	Type ChangeType
+	Key  string
+	Old  string
+	New  string
+}
+
+// The changes of one write, such as SetProperty, Load or Update,
+// not including changes of the default property list.
+type ChangeEvent struct {
+	Changes []Change
+}
+
+// Selects the keys a listener is notified of.
+type KeyFilter func(key string) bool
+
+// Selects the specified key.
+func Key(key string) KeyFilter {
+	return func(k string) bool {
+		return k == key
+	}
+}
+
+// Selects the keys starting with prefix.
+func Prefix(prefix string) KeyFilter {
+	return func(k string) bool {
+		return strings.HasPrefix(k, prefix)
+	}
+}
+
+// Calls fn with each change event of this property list, restricted to the
+// keys selected by any of the filters, or to all keys if there are none.
+// Events are delivered asynchronously and in order on a goroutine of the
+// listener, so a slow listener never blocks writers; pending events are
+// queued without bound. fn may read and modify p.
+// The returned function cancels the listener and releases its goroutine,
+// events that are pending then are discarded.
+func (p *Properties) OnChange(fn func(evt ChangeEvent), filters ...KeyFilter) (cancel func()) {
+	var l = &listener{
+		fn:      fn,
+		filters: filters,
+		signal:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	go l.run()
+
+	p.mutex.Lock()
+	p.listeners = append(p.listeners, l)
+	p.mutex.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			p.mutex.Lock()
+			for i, o := range p.listeners {
+				if o == l {
+					p.listeners = append(p.listeners[:i:i], p.listeners[i+1:]...)
+					break
+				}
+			}
+			p.mutex.Unlock()
+			close(l.done)
+		})
+	}
+}
+
+type listener struct {
+	fn      func(evt ChangeEvent)
+	filters []KeyFilter
+
+	mutex  sync.Mutex
+	queue  []ChangeEvent
+	signal chan struct{}
+	done   chan struct{}
+}
+
+// Queues the changes selected by the filters, never blocks.
+func (l *listener) notify(changes []Change) {
+	var selected = changes
+	if len(l.filters) > 0 {
+		selected = nil
+		for _, c := range changes {
+			for _, filter := range l.filters {
+				if filter(c.Key) {
+					selected = append(selected, c)
+					break
+				}
+			}
+		}
+		if len(selected) == 0 {
+			return
+		}
+	}
+
+	l.mutex.Lock()
+	l.queue = append(l.queue, ChangeEvent{Changes: selected})
+	l.mutex.Unlock()
+
+	select {
+	case l.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (l *listener) run() {
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-l.signal:
+		}
+
+		l.mutex.Lock()
+		var queue = l.queue
+		l.queue = nil
+		l.mutex.Unlock()
+
+		for _, evt := range queue {
+			select {
+			case <-l.done:
+				return
+			default:
+			}
+			l.fn(evt)
+		}
+	}
+}
+
+// Finishes a write: publishes the copy-on-write copy and notifies the
+// listeners. The caller holds the write lock, so events are queued in the
+// order of the writes.
+func (p *Properties) commitLocked(changes []Change) {
+	p.commitByLocked("", changes)
+}
+
+// Like commitLocked, recording the actor of the write in history mode.
+func (p *Properties) commitByLocked(actor string, changes []Change) {
+	p.publishLocked()
+	if len(changes) == 0 {
+		return
+	}
+	p.recordLocked(actor, changes)
+	for _, l := range p.listeners {
+		l.notify(changes)
+	}
+}
+
+// Appends the change of key from old to value, nil meaning absent.
+func appendChange(changes []Change, key, old, value interface{}) []Change {
+	switch {
+	case old == nil && value == nil:
+		return changes
+	case old == nil:
+		return append(changes, Change{Type: Added, Key: stringOf(key), New: stringOf(value)})
+	case value == nil:
+		return append(changes, Change{Type: Removed, Key: stringOf(key), Old: stringOf(old)})
+	}
+
+	var sOld, sNew = stringOf(old), stringOf(value)
+	if sOld == sNew {
+		return changes
+	}
+
+	return append(changes, Change{Type: Updated, Key: stringOf(key), Old: sOld, New: sNew})
+}
+
+// Merges the changes of the same key, keeping the first old and the last
+// new value, in the order of the first change of each key.
+func coalesce(changes []Change) []Change {
+	var index = make(map[string]int, len(changes))
+	var merged = make([]Change, 0, len(changes))
+	var exists = make([]bool, 0, len(changes))
+	for _, c := range changes {
+		i, ok := index[c.Key]
+		if !ok {
+			index[c.Key] = len(merged)
+			merged = append(merged, c)
+			exists = append(exists, c.Type != Added)
+			continue
+		}
+		merged[i].New = c.New
+		merged[i].Type = c.Type
+	}
+
+	var result = merged[:0]
+	for i, c := range merged {
+		var existed, exist = exists[i], c.Type != Removed
+		switch {
+		case !existed && !exist:
+			continue
+		case !existed:
+			c.Type, c.Old = Added, ""
+		case !exist:
+			c.Type, c.New = Removed, ""
+		default:
+			if c.Old == c.New {
+				continue
+			}
+			c.Type = Updated
+		}
+		result = append(result, c)
+	}
+
+	return result
+}
+
+// Returns the string form of a key or value.
+func stringOf(v interface{}) string {
+	if s, err := toString(v); err == nil {
+		return s
+	}
+
+	return fmt.Sprint(v)
+}
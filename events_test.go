@@ -0,0 +1,124 @@
+package properties
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProperties_OnChange(t *testing.T) {
+	p := NewSortedProperties(nil)
+	p.SetProperty("db.host", "old")
+	p.SetProperty("log.level", "info")
+
+	events := make(chan ChangeEvent, 16)
+	cancel := p.OnChange(func(evt ChangeEvent) {
+		events <- evt
+	})
+	dbEvents := make(chan ChangeEvent, 16)
+	cancelDB := p.OnChange(func(evt ChangeEvent) {
+		dbEvents <- evt
+	}, Prefix("db."), Key("app.name"))
+	defer cancelDB()
+
+	p.SetProperty("db.host", "new")
+	p.SetProperty("db.host", "new")
+	p.Remove("log.level")
+	p.Remove("missing")
+	if err := p.Load(strings.NewReader("db.port = 5432\napp.name = test\nx = 1\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.LoadFromXML(strings.NewReader(`<properties><entry key="x">2</entry></properties>`)); err != nil {
+		t.Fatal(err)
+	}
+	err := p.Update(func(tx *Tx) error {
+		tx.Set("y", "1")
+		tx.Remove("y")
+		tx.Set("x", "3")
+		tx.Set("x", "2")
+		tx.Set("db.host", "tx")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Reload(strings.NewReader("db.host = tx\nz = 1\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []ChangeEvent{
+		{[]Change{{Updated, "db.host", "old", "new"}}},
+		{[]Change{{Removed, "log.level", "info", ""}}},
+		{[]Change{{Added, "app.name", "", "test"}, {Added, "db.port", "", "5432"}, {Added, "x", "", "1"}}},
+		{[]Change{{Updated, "x", "1", "2"}}},
+		{[]Change{{Updated, "db.host", "new", "tx"}}},
+		{[]Change{{Removed, "app.name", "test", ""}, {Removed, "db.port", "5432", ""}, {Removed, "x", "2", ""}, {Added, "z", "", "1"}}},
+	}
+	for i, w := range want {
+		select {
+		case evt := <-events:
+			diff := cmp.Diff(evt, w)
+			if diff != "" {
+				t.Fatal(i, diff)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("missing event", i)
+		}
+	}
+
+	wantDB := []ChangeEvent{
+		{[]Change{{Updated, "db.host", "old", "new"}}},
+		{[]Change{{Added, "app.name", "", "test"}, {Added, "db.port", "", "5432"}}},
+		{[]Change{{Updated, "db.host", "new", "tx"}}},
+		{[]Change{{Removed, "app.name", "test", ""}, {Removed, "db.port", "5432", ""}}},
+	}
+	for i, w := range wantDB {
+		select {
+		case evt := <-dbEvents:
+			diff := cmp.Diff(evt, w)
+			if diff != "" {
+				t.Fatal(i, diff)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("missing event", i)
+		}
+	}
+
+	cancel()
+	cancel()
+	p.SetProperty("after", "cancel")
+	select {
+	case evt := <-events:
+		t.Fatal("event after cancel:", evt)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestProperties_OnChangeNonBlocking(t *testing.T) {
+	p := NewProperties()
+	block := make(chan struct{})
+	got := make(chan int, 1)
+	var n int
+	cancel := p.OnChange(func(evt ChangeEvent) {
+		<-block
+		if n++; n == 100 {
+			got <- n
+		}
+	})
+	defer cancel()
+
+	for i := 0; i < 100; i++ {
+		p.SetProperty("k", strings.Repeat("v", i+1))
+	}
+	close(block)
+	select {
+	case n := <-got:
+		diff := cmp.Diff(n, 100)
+		if diff != "" {
+			t.Fatal(diff)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("events lost")
+	}
+}
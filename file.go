@@ -0,0 +1,240 @@
+package properties
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Extension of XML property files.
+const XMLExt = ".xml"
+
+// Options of OpenFile. The zero value polls every second
+// without debouncing.
+type FileOptions struct {
+	// The interval between two stats of the file, one second if 0.
+	Interval time.Duration
+	// How long the size and modification time of the file must stay
+	// the same before it is reloaded, so a file that is being written is
+	// not read half way. 0 reloads at the first poll that sees a change.
+	Debounce time.Duration
+	// Called with the error of a poll that fails to stat, read or parse
+	// the file. The last good properties are kept.
+	OnError func(err error)
+	// Called with the changes of each reload that changes the properties,
+	// sorted by key.
+	OnReload func(changes []Change)
+}
+
+// A property list kept in sync with a property file by polling.
+// The properties are replaced by the content of the file at each reload,
+// so writes through the Properties methods only last until the next one.
+type File struct {
+	*Properties
+	name string
+	opts FileOptions
+
+	// Serializes the polls.
+	mutex     sync.Mutex
+	stat      fileStat
+	changedAt time.Time
+	pending   bool
+	sum       [sha256.Size]byte
+	lastErr   string
+
+	done chan struct{}
+	once sync.Once
+}
+
+// The attributes of a file compared to detect a change.
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+// Loads the property file with the specified name, in XML if its extension
+// is XMLExt, and polls it in the background, reloading the properties
+// atomically whenever the file changes, see FileOptions. A change is
+// detected from the size and modification time, and a file whose content
+// is the same as the last loaded one is not reloaded. Returns an error if
+// the initial load fails. Close stops the polling.
+func OpenFile(name string, opts *FileOptions) (*File, error) {
+	var f = &File{
+		Properties: NewProperties(),
+		name:       name,
+		done:       make(chan struct{}),
+	}
+	if opts != nil {
+		f.opts = *opts
+	}
+	if f.opts.Interval <= 0 {
+		f.opts.Interval = time.Second
+	}
+
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	f.stat = fileStat{modTime: info.ModTime(), size: info.Size()}
+	if _, err = f.load(); err != nil {
+		return nil, err
+	}
+
+	go f.run()
+
+	return f, nil
+}
+
+// Returns the name of the file.
+func (f *File) Name() string {
+	return f.name
+}
+
+// Reads the file now, without debouncing, and reloads the properties if
+// its content changed. Returns the changes of the reload, sorted by key.
+// On error the last good properties are kept.
+func (f *File) Check() ([]Change, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	info, err := os.Stat(f.name)
+	if err != nil {
+		return nil, err
+	}
+	f.stat = fileStat{modTime: info.ModTime(), size: info.Size()}
+	f.pending = false
+
+	return f.load()
+}
+
+// Stops polling the file and waits for a reload in progress to finish, so
+// the properties are not reloaded once it returns. It does not wait for the
+// OnError or OnReload call of that poll, so it may be called from them.
+// The properties remain usable.
+func (f *File) Close() error {
+	f.once.Do(func() {
+		close(f.done)
+	})
+	f.mutex.Lock()
+	f.mutex.Unlock()
+
+	return nil
+}
+
+func (f *File) run() {
+	var ticker = time.NewTicker(f.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.done:
+			return
+		case <-ticker.C:
+			f.poll()
+		}
+	}
+}
+
+// Stats the file and reloads it once it changed and settled. OnError and
+// OnReload are called after f.mutex is released, so they may call Check or
+// Close.
+func (f *File) poll() {
+	var notify = f.pollLocked()
+	if notify != nil {
+		notify()
+	}
+}
+
+// Does the work of poll holding f.mutex and returns the callback to call
+// once it is released, nil if none.
+func (f *File) pollLocked() func() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	select {
+	case <-f.done:
+		return nil
+	default:
+	}
+	info, err := os.Stat(f.name)
+	if err != nil {
+		return f.report(err)
+	}
+
+	var stat = fileStat{modTime: info.ModTime(), size: info.Size()}
+	if !stat.modTime.Equal(f.stat.modTime) || stat.size != f.stat.size {
+		f.stat = stat
+		f.changedAt = time.Now()
+		f.pending = true
+		f.lastErr = ""
+	}
+	if !f.pending || time.Since(f.changedAt) < f.opts.Debounce {
+		return nil
+	}
+	f.pending = false
+
+	changes, err := f.load()
+	if err != nil {
+		return f.report(err)
+	}
+	f.lastErr = ""
+	if len(changes) == 0 || f.opts.OnReload == nil {
+		return nil
+	}
+
+	return func() {
+		f.opts.OnReload(changes)
+	}
+}
+
+// Returns the call of OnError with the error, once until the file changes,
+// a poll succeeds or the error changes, nil otherwise.
+func (f *File) report(err error) func() {
+	if err.Error() == f.lastErr {
+		return nil
+	}
+	f.lastErr = err.Error()
+	if f.opts.OnError == nil {
+		return nil
+	}
+
+	return func() {
+		f.opts.OnError(err)
+	}
+}
+
+// Reads and parses the file and replaces the properties if its content
+// changed. The caller holds f.mutex.
+func (f *File) load() ([]Change, error) {
+	data, err := os.ReadFile(f.name)
+	if err != nil {
+		return nil, err
+	}
+	var sum = sha256.Sum256(data)
+	if sum == f.sum {
+		return nil, nil
+	}
+
+	var table Hashtable
+	if strings.EqualFold(filepath.Ext(f.name), XMLExt) {
+		table = f.newHashtable()
+		err = load(table, bytes.NewReader(data))
+	} else {
+		table, err = f.parse(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, &os.PathError{Op: "load", Path: f.name, Err: err}
+	}
+
+	changes, err := f.replace(table)
+	if err != nil {
+		return nil, err
+	}
+	f.sum = sum
+
+	return changes, nil
+}
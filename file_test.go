@@ -0,0 +1,126 @@
+package properties
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOpenFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "app.properties")
+	if err := os.WriteFile(name, []byte("a = 1\nb = 1\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	reloads := make(chan []Change, 4)
+	errs := make(chan error, 4)
+	f, err := OpenFile(name, &FileOptions{
+		Interval: 5 * time.Millisecond,
+		Debounce: 10 * time.Millisecond,
+		OnReload: func(changes []Change) { reloads <- changes },
+		OnError:  func(err error) { errs <- err },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	diff := cmp.Diff([]interface{}{f.Name(), f.GetPropertyByDefault("a", "")}, []interface{}{name, "1"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	if err = os.WriteFile(name, []byte("a = 2\nc = 1\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case changes := <-reloads:
+		diff = cmp.Diff(changes, []Change{{Updated, "a", "1", "2"}, {Removed, "b", "1", ""}, {Added, "c", "", "1"}})
+		if diff != "" {
+			t.Fatal(diff)
+		}
+	case err = <-errs:
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("file not reloaded")
+	}
+
+	if err = os.WriteFile(name, []byte("a = \\u00"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err = <-errs:
+	case changes := <-reloads:
+		t.Fatal("malformed file reloaded:", changes)
+	case <-time.After(5 * time.Second):
+		t.Fatal("error not reported")
+	}
+	diff = cmp.Diff(f.GetPropertyByDefault("a", ""), "2")
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(name, []byte("a = 3\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := f.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff(changes, []Change{{Updated, "a", "2", "3"}, {Removed, "c", "1", ""}})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	if changes, err = f.Check(); err != nil || changes != nil {
+		t.Fatal(changes, err)
+	}
+
+	if _, err = OpenFile(filepath.Join(t.TempDir(), "missing.properties"), nil); !os.IsNotExist(err) {
+		t.Fatal("missing file opened:", err)
+	}
+}
+
+func TestOpenFile_CloseFromCallback(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "app.properties")
+	if err := os.WriteFile(name, []byte("a = 1\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	var f *File
+	done := make(chan error, 1)
+	f, err := OpenFile(name, &FileOptions{
+		Interval: 5 * time.Millisecond,
+		OnReload: func(changes []Change) {
+			if _, err := f.Check(); err != nil {
+				done <- err
+				return
+			}
+			done <- f.Close()
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if err = os.WriteFile(name, []byte("a = 2\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err = <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Check or Close blocked in OnReload")
+	}
+	diff := cmp.Diff(f.GetPropertyByDefault("a", ""), "2")
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
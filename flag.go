@@ -0,0 +1,106 @@
+package properties
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// Defaults the flags of the specified FlagSet from this property list:
+// a flag whose name is a property key, including keys in the default
+// property list, takes the property value as its default value.
+// Call before fs.Parse.
+func (p *Properties) BindFlagSet(fs *flag.FlagSet) error {
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+		if _, ok := f.Value.(*defineValue); ok {
+			return
+		}
+		val, exist := p.GetProperty(f.Name)
+		if !exist {
+			return
+		}
+		if err = f.Value.Set(val); err != nil {
+			err = fmt.Errorf("invalid value %q for flag -%s: %v", val, f.Name, err)
+			return
+		}
+		f.DefValue = f.Value.String()
+	})
+
+	return err
+}
+
+// Copies the flags explicitly set on the command line into this property
+// list, overriding the values they defaulted from, and the default values
+// of the other flags that have no property, including keys in the default
+// property list, so this property list holds the effective configuration.
+// Call after fs.Parse.
+func (p *Properties) ApplyFlagSet(fs *flag.FlagSet) {
+	var set = make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	fs.VisitAll(func(f *flag.Flag) {
+		if _, ok := f.Value.(*defineValue); ok {
+			return
+		}
+		if !set[f.Name] {
+			if _, exist := p.GetProperty(f.Name); exist {
+				return
+			}
+		}
+		p.SetProperty(f.Name, f.Value.String())
+	})
+}
+
+// Call p.BindFlagSet(fs), fs.Parse(arguments) and p.ApplyFlagSet(fs).
+func (p *Properties) ParseFlagSet(fs *flag.FlagSet, arguments []string) error {
+	if err := p.BindFlagSet(fs); err != nil {
+		return err
+	}
+	if err := fs.Parse(arguments); err != nil {
+		return err
+	}
+	p.ApplyFlagSet(fs)
+
+	return nil
+}
+
+// Defines a repeatable flag with the specified name and usage on fs, every
+// occurrence of the form -name key=value sets an arbitrary property, like
+// the -D option of the java launcher. A definition without '=' sets the
+// key to "".
+func (p *Properties) DefineVar(fs *flag.FlagSet, name, usage string) {
+	fs.Var(&defineValue{props: p}, name, usage)
+}
+
+// flag.Value of the flag registered by DefineVar.
+type defineValue struct {
+	props *Properties
+}
+
+func (d *defineValue) String() string {
+	return ""
+}
+
+func (d *defineValue) Set(s string) error {
+	key, value := splitDefine(s)
+	if key == "" {
+		return fmt.Errorf("missing property key in %q", s)
+	}
+	d.props.SetProperty(key, value)
+
+	return nil
+}
+
+// Splits a "key=value" definition, a definition without '=' has an empty value.
+func splitDefine(s string) (key, value string) {
+	if i := strings.Index(s, "="); i >= 0 {
+		return s[:i], s[i+1:]
+	}
+
+	return s, ""
+}
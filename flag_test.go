@@ -0,0 +1,73 @@
+package properties
+
+import (
+	"flag"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+	"time"
+)
+
+func TestProperties_ParseFlagSet(t *testing.T) {
+	p := NewProperties()
+	p.SetProperty("host", "db.internal")
+	p.SetProperty("port", "5432")
+	p.SetProperty("debug", "true")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	host := fs.String("host", "localhost", "database host")
+	port := fs.Int("port", 0, "database port")
+	debug := fs.Bool("debug", false, "debug mode")
+	fs.Duration("timeout", 30*time.Second, "request timeout")
+	p.DefineVar(fs, "D", "set a property, -D key=value")
+
+	err := p.ParseFlagSet(fs, []string{"-port", "6543", "-D", "log.level=info", "-D=cache.enabled", "arg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff := cmp.Diff([]interface{}{*host, *port, *debug}, []interface{}{"db.internal", 6543, true})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(fs.Lookup("host").DefValue, "db.internal")
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(fs.Args(), []string{"arg"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	tests := map[string]string{
+		"host":          "db.internal",
+		"port":          "6543",
+		"debug":         "true",
+		"log.level":     "info",
+		"cache.enabled": "",
+		"timeout":       "30s",
+	}
+	for key, val := range tests {
+		v, exist := p.GetProperty(key)
+		if !exist {
+			t.Fatal("missing property", key)
+		}
+		diff = cmp.Diff(v, val)
+		if diff != "" {
+			t.Fatal(key, diff)
+		}
+	}
+	if _, exist := p.GetProperty("D"); exist {
+		t.Fatal("define flag stored as property")
+	}
+}
+
+func TestProperties_BindFlagSet(t *testing.T) {
+	p := NewProperties()
+	p.SetProperty("port", "not-a-number")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Int("port", 0, "database port")
+	if err := p.BindFlagSet(fs); err == nil {
+		t.Fatal("invalid property value accepted")
+	}
+}
@@ -0,0 +1,76 @@
+package properties
+
+import (
+	"errors"
+)
+
+// Returned, or panicked with, by the writes of a frozen property list.
+var ErrFrozen = errors.New("properties: property list is frozen")
+
+type freezeMode int
+
+const (
+	notFrozen freezeMode = iota
+	frozenErrors
+	frozenStrict
+)
+
+// Makes this property list read-only for good. Afterwards the writes that
+// return an error, such as Load, LoadFromXML, Reload, Update, Rollback and
+// SetDefaults, return ErrFrozen, and the writes that cannot, such as
+// SetProperty, Put, Remove and Clear, panic with ErrFrozen. Nothing is
+// changed in either case. The default property list is not frozen.
+func (p *Properties) Freeze() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.frozen == notFrozen {
+		p.frozen = frozenErrors
+	}
+}
+
+// Like Freeze, but every write panics with ErrFrozen, so a write whose
+// error is ignored is caught too.
+func (p *Properties) FreezeStrict() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.frozen = frozenStrict
+}
+
+// Reports whether this property list is frozen.
+func (p *Properties) Frozen() bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.frozen != notFrozen
+}
+
+// Like writableLocked, taking the read lock.
+func (p *Properties) writable() error {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.writableLocked()
+}
+
+// Returns ErrFrozen, or panics in strict mode, if this property list is
+// frozen. The caller holds the lock.
+func (p *Properties) writableLocked() error {
+	switch p.frozen {
+	case frozenErrors:
+		return ErrFrozen
+	case frozenStrict:
+		panic(ErrFrozen)
+	}
+
+	return nil
+}
+
+// Panics if this property list is frozen, for the writes that cannot
+// return an error. The caller holds the lock.
+func (p *Properties) mustWritableLocked() {
+	if p.frozen != notFrozen {
+		panic(ErrFrozen)
+	}
+}
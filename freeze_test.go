@@ -0,0 +1,65 @@
+package properties
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+)
+
+func TestProperties_Freeze(t *testing.T) {
+	p := NewProperties()
+	p.SetProperty("a", "1")
+	p.Freeze()
+	p.Freeze()
+
+	errs := []error{
+		p.Load(strings.NewReader("a = 2")),
+		p.LoadFromXML(strings.NewReader(`<properties><entry key="a">2</entry></properties>`)),
+		p.Reload(strings.NewReader("a = 2")),
+		p.Update(func(tx *Tx) error { tx.Set("a", "2"); return nil }),
+		p.SetDefaults(NewProperties()),
+	}
+	_, err := Merge(p, NewProperties(), MergeOverwrite)
+	errs = append(errs, err)
+	for i, err := range errs {
+		if err != ErrFrozen {
+			t.Fatal(i, "not frozen:", err)
+		}
+	}
+
+	writes := []func(){
+		func() { p.SetProperty("a", "2") },
+		func() { p.Put("b", "2") },
+		func() { p.Remove("a") },
+		func() { p.Clear() },
+		func() { p.PutIfAbsent("b", "2") },
+	}
+	for i, write := range writes {
+		if r := mustPanic(write); r != ErrFrozen {
+			t.Fatal(i, "not frozen:", r)
+		}
+	}
+
+	diff := cmp.Diff([]interface{}{p.Frozen(), p.ToMap()}, []interface{}{true, map[interface{}]interface{}{"a": "1"}})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	p.FreezeStrict()
+	if r := mustPanic(func() { _ = p.Load(strings.NewReader("a = 2")) }); r != ErrFrozen {
+		t.Fatal("not frozen:", r)
+	}
+	diff = cmp.Diff(p.GetPropertyByDefault("a", ""), "1")
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func mustPanic(fn func()) (r interface{}) {
+	defer func() {
+		r = recover()
+	}()
+	fn()
+
+	return nil
+}
@@ -1,14 +1,28 @@
 package properties
 
-import "sync"
+import (
+	"iter"
+	"sync"
+)
 
 type Hashtable interface {
 	New() Hashtable
 	Put(key, value interface{}) interface{}
 	Get(key interface{}) interface{}
-	Remove(key interface{})
+	// Removes the key and returns its value, nil if it was absent.
+	Remove(key interface{}) interface{}
+	// Removes all keys.
+	Clear()
+	ContainsKey(key interface{}) bool
 	Size() int
 	Keys() []interface{}
+
+	// Calls fn for each key and value in iteration order until fn returns
+	// false, without allocating. fn must not call any method of the
+	// hashtable, which may hold its lock during the iteration.
+	Range(fn func(key, value interface{}) bool)
+	// Returns an iterator over the keys and values, see Range.
+	All() iter.Seq2[interface{}, interface{}]
 }
 
 type hashtable struct {
@@ -43,11 +57,30 @@ func (h *hashtable) Get(key interface{}) interface{} {
 	return h.mapper[key]
 }
 
-func (h *hashtable) Remove(key interface{}) {
+func (h *hashtable) Remove(key interface{}) interface{} {
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
 
+	var old = h.mapper[key]
 	delete(h.mapper, key)
+
+	return old
+}
+
+func (h *hashtable) Clear() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.mapper = map[interface{}]interface{}{}
+}
+
+func (h *hashtable) ContainsKey(key interface{}) bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	_, ok := h.mapper[key]
+
+	return ok
 }
 
 func (h *hashtable) Size() int {
@@ -68,3 +101,18 @@ func (h *hashtable) Keys() []interface{} {
 
 	return keys
 }
+
+func (h *hashtable) Range(fn func(key, value interface{}) bool) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	for key, value := range h.mapper {
+		if !fn(key, value) {
+			return
+		}
+	}
+}
+
+func (h *hashtable) All() iter.Seq2[interface{}, interface{}] {
+	return h.Range
+}
@@ -1,6 +1,7 @@
 package properties
 
 import (
+	"fmt"
 	"github.com/google/go-cmp/cmp"
 	"testing"
 )
@@ -40,3 +41,41 @@ func TestNewHashtable(t *testing.T) {
 	t.Log("remove key2 size:", h.Size())
 	t.Log(h.Keys())
 }
+
+func TestHashtable_Range(t *testing.T) {
+	for _, h := range []Hashtable{NewHashtable(), NewHashtable2(), NewSortedHashtable(nil), ToHashtable(NewTable[string, int]())} {
+		for i := 0; i < 10; i++ {
+			h.Put(fmt.Sprint("k", i), i)
+		}
+		var sum int
+		h.Range(func(key, value interface{}) bool {
+			sum += value.(int)
+			return true
+		})
+		diff := cmp.Diff(sum, 45)
+		if diff != "" {
+			t.Fatal(diff)
+		}
+
+		var n int
+		for range h.All() {
+			if n++; n == 3 {
+				break
+			}
+		}
+		diff = cmp.Diff(n, 3)
+		if diff != "" {
+			t.Fatal(diff)
+		}
+	}
+
+	h := NewHashtable()
+	h.Put("k", 1)
+	allocs := testing.AllocsPerRun(100, func() {
+		h.Range(func(key, value interface{}) bool { return true })
+	})
+	diff := cmp.Diff(allocs, 0.0)
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
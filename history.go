@@ -0,0 +1,156 @@
+package properties
+
+import (
+	"errors"
+	"time"
+)
+
+// Returned by Rollback and DiffSince when history mode is disabled.
+var ErrNoHistory = errors.New("properties: history mode is disabled")
+
+// Returned by Rollback and DiffSince for a version that is newer than the
+// current version or older than the oldest revision kept.
+var ErrVersionUnavailable = errors.New("properties: version not in history")
+
+// The changes of one write recorded in history mode.
+type Revision struct {
+	// The version of the property list after the write.
+	Version uint64
+	Time    time.Time
+	// Who made the write, see UpdateBy, empty if unknown.
+	Actor   string
+	Changes []Change
+}
+
+type history struct {
+	limit     int
+	base      uint64
+	revisions []Revision
+}
+
+// Enables history mode keeping the latest limit revisions, or all of them
+// if limit is negative, or disables it if limit is 0. In history mode every
+// write of this property list, not of its default property list, is
+// recorded as a Revision, so it can be inspected with DiffSince and undone
+// with Rollback. Changing the limit keeps the recorded revisions that fit.
+func (p *Properties) SetHistory(limit int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if limit == 0 {
+		p.history = nil
+		return
+	}
+	if p.history == nil {
+		p.history = &history{base: p.version}
+	}
+	p.history.limit = limit
+	p.history.trim()
+}
+
+// Returns the version of this property list, incremented by every write
+// that changes it, whether or not history mode is enabled.
+func (p *Properties) Version() uint64 {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.version
+}
+
+// Returns the recorded revisions, oldest first,
+// nil if history mode is disabled.
+func (p *Properties) History() []Revision {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if p.history == nil {
+		return nil
+	}
+
+	return append([]Revision(nil), p.history.revisions...)
+}
+
+// Returns the changes made since the version, merged per key,
+// in the order of the first change of each key.
+func (p *Properties) DiffSince(version uint64) ([]Change, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.diffSinceLocked(version)
+}
+
+// Restores the properties of this property list to the version by undoing
+// the changes made since, atomically. The rollback is itself a write with
+// a new version, so it can be rolled back in turn.
+// History is recorded in the string form of keys and values, so a key or
+// value that is not a string is restored in its string form.
+func (p *Properties) Rollback(version uint64) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err := p.writableLocked(); err != nil {
+		return err
+	}
+	diff, err := p.diffSinceLocked(version)
+	if err != nil {
+		return err
+	}
+
+	var changes []Change
+	for _, c := range diff {
+		if c.Type == Added {
+			changes = appendChange(changes, c.Key, p.Hashtable.Remove(c.Key), nil)
+		} else {
+			changes = appendChange(changes, c.Key, p.Hashtable.Put(c.Key, c.Old), c.Old)
+		}
+	}
+	p.commitLocked(changes)
+
+	return nil
+}
+
+func (p *Properties) diffSinceLocked(version uint64) ([]Change, error) {
+	if p.history == nil {
+		return nil, ErrNoHistory
+	}
+	if version < p.history.base || version > p.version {
+		return nil, ErrVersionUnavailable
+	}
+
+	var changes []Change
+	for _, r := range p.history.revisions {
+		if r.Version > version {
+			changes = append(changes, r.Changes...)
+		}
+	}
+
+	return coalesce(changes), nil
+}
+
+// Counts a write and records it in history mode,
+// the caller holds the write lock.
+func (p *Properties) recordLocked(actor string, changes []Change) {
+	p.version++
+	if p.history == nil {
+		return
+	}
+
+	p.history.revisions = append(p.history.revisions, Revision{
+		Version: p.version,
+		Time:    time.Now(),
+		Actor:   actor,
+		Changes: changes,
+	})
+	p.history.trim()
+}
+
+// Drops the oldest revisions beyond the limit.
+func (h *history) trim() {
+	if h.limit < 0 || len(h.revisions) <= h.limit {
+		return
+	}
+
+	var drop = len(h.revisions) - h.limit
+	h.base = h.revisions[drop-1].Version
+	h.revisions = append([]Revision(nil), h.revisions[drop:]...)
+}
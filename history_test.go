@@ -0,0 +1,86 @@
+package properties
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+)
+
+func TestProperties_Rollback(t *testing.T) {
+	p := NewSortedProperties(nil)
+	p.SetProperty("a", "1")
+	if err := p.Rollback(0); err != ErrNoHistory {
+		t.Fatal("history not disabled:", err)
+	}
+
+	p.SetHistory(-1)
+	v1 := p.Version()
+	p.SetProperty("a", "2")
+	p.SetProperty("a", "2")
+	err := p.UpdateBy("ops", func(tx *Tx) error {
+		tx.Set("b", "1")
+		tx.Remove("a")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Load(strings.NewReader("c = 1\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	history := p.History()
+	diff := cmp.Diff([]interface{}{v1, p.Version(), len(history), history[1].Actor, history[1].Changes}, []interface{}{
+		uint64(1), uint64(4), 3, "ops", []Change{{Added, "b", "", "1"}, {Removed, "a", "2", ""}},
+	})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	changes, err := p.DiffSince(v1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff(changes, []Change{{Removed, "a", "1", ""}, {Added, "b", "", "1"}, {Added, "c", "", "1"}})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	if err = p.Rollback(v1); err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff([]interface{}{p.ToMap(), p.Version()}, []interface{}{map[interface{}]interface{}{"a": "1"}, uint64(5)})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	if err = p.Rollback(4); err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff(p.ToMap(), map[interface{}]interface{}{"b": "1", "c": "1"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	p.SetHistory(2)
+	diff = cmp.Diff(len(p.History()), 2)
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	for _, v := range []uint64{3, 7} {
+		if _, err = p.DiffSince(v); err != ErrVersionUnavailable {
+			t.Fatal("version", v, "available:", err)
+		}
+	}
+	if err = p.Rollback(5); err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff(p.ToMap(), map[interface{}]interface{}{"a": "1"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	p.SetHistory(0)
+	if _, err = p.DiffSince(p.Version()); err != ErrNoHistory {
+		t.Fatal("history not disabled:", err)
+	}
+}
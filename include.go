@@ -0,0 +1,123 @@
+package properties
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Reserved key of the include directive. The element is a comma separated
+// list of files or glob patterns, resolved relative to the including file:
+//
+//	@include = common.properties, optional:local/*.properties
+//
+// Included files are loaded at the position of the directive, so entries
+// after it override included ones. Glob matches are loaded in lexical order.
+const IncludeKey = "@include"
+
+// Prefix of an include that is skipped instead of failing when no file matches.
+const OptionalPrefix = "optional:"
+
+// Resolves, globs and opens include files.
+type includer struct {
+	open func(name string) (io.ReadCloser, error)
+	glob func(pattern string) ([]string, error)
+	join func(dir, name string) string
+	dir  func(name string) string
+}
+
+var osIncluder = &includer{
+	open: func(name string) (io.ReadCloser, error) { return os.Open(name) },
+	glob: filepath.Glob,
+	join: func(dir, name string) string {
+		if filepath.IsAbs(name) {
+			return filepath.Clean(name)
+		}
+		return filepath.Join(dir, name)
+	},
+	dir: filepath.Dir,
+}
+
+func fsIncluder(fsys fs.FS) *includer {
+	return &includer{
+		open: func(name string) (io.ReadCloser, error) { return fsys.Open(name) },
+		glob: func(pattern string) ([]string, error) { return fs.Glob(fsys, pattern) },
+		join: func(dir, name string) string { return path.Join(dir, name) },
+		dir:  path.Dir,
+	}
+}
+
+// Reads the property file with the specified name, processing include
+// directives (see IncludeKey). Nothing is changed if the file or any
+// included file fails to load.
+func (p *Properties) LoadIncludeFile(name string) error {
+	return p.loadInclude(osIncluder, filepath.Clean(name))
+}
+
+// Reads the property file with the specified name from fsys, processing
+// include directives (see IncludeKey). Nothing is changed if the file or
+// any included file fails to load.
+func (p *Properties) LoadIncludeFS(fsys fs.FS, name string) error {
+	return p.loadInclude(fsIncluder(fsys), path.Clean(name))
+}
+
+func (p *Properties) loadInclude(inc *includer, name string) error {
+	var table = p.newHashtable()
+	if err := p.include(inc, table, nil, name, nil); err != nil {
+		return err
+	}
+
+	return p.merge(table)
+}
+
+// Loads the file into table, stack holds the files including it.
+// If origin is not nil, it records the file that set each key.
+func (p *Properties) include(inc *includer, table Hashtable, origin map[string]string, name string, stack []string) error {
+	for _, parent := range stack {
+		if parent == name {
+			return errors.New("include cycle: " + strings.Join(append(stack, name), " -> "))
+		}
+	}
+	stack = append(stack, name)
+
+	file, err := inc.open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return p.load0(NewLineReader(file), func(key, value string) error {
+		if key != IncludeKey {
+			table.Put(key, value)
+			if origin != nil {
+				origin[key] = name
+			}
+			return nil
+		}
+		for _, pattern := range strings.Split(value, ",") {
+			pattern = strings.TrimSpace(pattern)
+			var optional = strings.HasPrefix(pattern, OptionalPrefix)
+			pattern = strings.TrimPrefix(pattern, OptionalPrefix)
+			if pattern == "" {
+				continue
+			}
+			names, err := inc.glob(inc.join(inc.dir(name), pattern))
+			if err != nil {
+				return err
+			}
+			if len(names) == 0 && !optional {
+				return errors.New(name + ": include <" + pattern + "> not found")
+			}
+			for _, n := range names {
+				if err = p.include(inc, table, origin, n, stack); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,76 @@
+package properties
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestProperties_LoadIncludeFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app/service.properties": {Data: []byte("name = service\n@include = ../common/*.properties, optional:local.properties\ndb.host = service-db\n")},
+		"common/db.properties":   {Data: []byte("db.host = common-db\ndb.port = 5432\n")},
+		"common/log.properties":  {Data: []byte("log.level = info\n")},
+		"cycle/a.properties":     {Data: []byte("@include = b.properties\n")},
+		"cycle/b.properties":     {Data: []byte("@include = a.properties\n")},
+		"missing/a.properties":   {Data: []byte("a = 1\n@include = b.properties\n")},
+	}
+
+	p := NewProperties()
+	if err := p.LoadIncludeFS(fsys, "app/service.properties"); err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"name":      "service",
+		"db.host":   "service-db",
+		"db.port":   "5432",
+		"log.level": "info",
+	}
+	diff := cmp.Diff(p.Size(), len(tests))
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	for key, val := range tests {
+		diff = cmp.Diff(p.GetPropertyByDefault(key, ""), val)
+		if diff != "" {
+			t.Fatal(key, diff)
+		}
+	}
+
+	p = NewProperties()
+	err := p.LoadIncludeFS(fsys, "cycle/a.properties")
+	if err == nil || !strings.Contains(err.Error(), "include cycle: cycle/a.properties -> cycle/b.properties -> cycle/a.properties") {
+		t.Fatal("cycle not detected:", err)
+	}
+
+	err = p.LoadIncludeFS(fsys, "missing/a.properties")
+	if err == nil {
+		t.Fatal("missing include not reported")
+	}
+	diff = cmp.Diff(p.Size(), 0)
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestProperties_LoadIncludeFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "common.properties"), []byte("a = common\nb = common\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app.properties"), []byte("@include = common.properties\nb = app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewProperties()
+	if err := p.LoadIncludeFile(filepath.Join(dir, "app.properties")); err != nil {
+		t.Fatal(err)
+	}
+	diff := cmp.Diff([]string{p.GetPropertyByDefault("a", ""), p.GetPropertyByDefault("b", "")}, []string{"common", "app"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,172 @@
+package properties
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Prefix of the java launcher system property option.
+const SystemPropertyPrefix = "-D"
+
+// Sets the properties of the -Dkey=value arguments atomically and returns
+// the other arguments in order. An argument -Dkey sets the key to "".
+// If a key is defined more than once, the last definition wins.
+func (p *Properties) LoadArgs(args []string) ([]string, error) {
+	var table = p.newHashtable()
+	var rest = make([]string, 0, len(args))
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, SystemPropertyPrefix) || len(arg) == len(SystemPropertyPrefix) {
+			rest = append(rest, arg)
+			continue
+		}
+		key, value := splitDefine(arg[len(SystemPropertyPrefix):])
+		if key == "" {
+			rest = append(rest, arg)
+			continue
+		}
+		table.Put(key, value)
+	}
+	if err := p.merge(table); err != nil {
+		return nil, err
+	}
+
+	return rest, nil
+}
+
+// Splits options such as the value of JAVA_TOOL_OPTIONS or JAVA_OPTS with
+// SplitArgs and sets the properties of the -D arguments, the other
+// arguments are returned in order.
+func (p *Properties) LoadJavaOptions(options string) ([]string, error) {
+	args, err := SplitArgs(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.LoadArgs(args)
+}
+
+// Returns the properties, including the default property list, as
+// -Dkey=value arguments sorted by key, suitable for exec.Command.
+func (p *Properties) SystemArgs() []string {
+	var keys = p.StringPropertyNames()
+	sort.Strings(keys)
+
+	var args = make([]string, 0, len(keys))
+	for _, key := range keys {
+		val, _ := p.GetProperty(key)
+		args = append(args, SystemPropertyPrefix+key+"="+val)
+	}
+
+	return args
+}
+
+// Returns the -D arguments of SystemArgs joined into a single quoted
+// string, suitable for JAVA_TOOL_OPTIONS or JAVA_OPTS.
+func (p *Properties) JavaOptions() string {
+	return JoinArgs(p.SystemArgs())
+}
+
+// Splits a command line into arguments using the shell quoting rules:
+// arguments are separated by unquoted white space, characters within
+// single quotes are literal, within double quotes a backslash escapes
+// '"', '\\', '$' and '`', and outside of quotes a backslash escapes any
+// character.
+func SplitArgs(s string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	var inArg bool
+
+	for i := 0; i < len(s); i++ {
+		var c = s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		case c == '\'':
+			inArg = true
+			var end = strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, errors.New("unterminated single quote")
+			}
+			arg.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+		case c == '"':
+			inArg = true
+			var closed bool
+			for i++; i < len(s); i++ {
+				c = s[i]
+				if c == '"' {
+					closed = true
+					break
+				}
+				if c == '\\' && i+1 < len(s) && strings.IndexByte("\"\\$`", s[i+1]) >= 0 {
+					i++
+					c = s[i]
+				}
+				arg.WriteByte(c)
+			}
+			if !closed {
+				return nil, errors.New("unterminated double quote")
+			}
+		case c == '\\':
+			inArg = true
+			if i+1 == len(s) {
+				return nil, errors.New("trailing backslash")
+			}
+			i++
+			arg.WriteByte(s[i])
+		default:
+			inArg = true
+			arg.WriteByte(c)
+		}
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+
+	return args, nil
+}
+
+// Quotes an argument so that SplitArgs, a POSIX shell and the JVM parser
+// of JAVA_TOOL_OPTIONS all read it back unchanged. Quoting never relies on
+// backslashes, which the JVM does not interpret.
+func QuoteArg(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	if strings.IndexFunc(arg, needQuote) < 0 {
+		return arg
+	}
+	if !strings.Contains(arg, "'") {
+		return "'" + arg + "'"
+	}
+	if strings.IndexAny(arg, "\"\\$`") < 0 {
+		return `"` + arg + `"`
+	}
+
+	// Close the single quotes around each single quote and double quote it.
+	return "'" + strings.Replace(arg, "'", `'"'"'`, -1) + "'"
+}
+
+// Quotes each argument with QuoteArg and joins them with a space.
+func JoinArgs(args []string) string {
+	var quoted = make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = QuoteArg(arg)
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+func needQuote(r rune) bool {
+	switch {
+	case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		return false
+	}
+
+	return !strings.ContainsRune("-_=.,:/@%+", r)
+}
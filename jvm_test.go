@@ -0,0 +1,80 @@
+package properties
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := map[string][]string{
+		``:                             nil,
+		`  -Xmx1g   -Da=b `:            {"-Xmx1g", "-Da=b"},
+		`-Dmsg='hello world'`:          {"-Dmsg=hello world"},
+		`-Dmsg="say \"hi\" \$HOME \n"`: {`-Dmsg=say "hi" $HOME \n`},
+		`-Dpath=C:\\tmp -Dsp=a\ b`:     {`-Dpath=C:\tmp`, "-Dsp=a b"},
+		`-Dq='it'"'"'s' ''`:            {"-Dq=it's", ""},
+	}
+	for s, args := range tests {
+		got, err := SplitArgs(s)
+		if err != nil {
+			t.Fatal(s, err)
+		}
+		diff := cmp.Diff(got, args)
+		if diff != "" {
+			t.Fatal(s, diff)
+		}
+	}
+
+	for _, s := range []string{`'open`, `"open`, `trailing\`} {
+		if _, err := SplitArgs(s); err == nil {
+			t.Fatal("no error for", s)
+		}
+	}
+}
+
+func TestJoinArgs(t *testing.T) {
+	args := []string{"-Dplain=v1.2", "-Dspace=a b", "-Dq=it's", `-Dmix=it's "$x"`, ""}
+	s := JoinArgs(args)
+	diff := cmp.Diff(s, `-Dplain=v1.2 '-Dspace=a b' "-Dq=it's" '-Dmix=it'"'"'s "$x"' ''`)
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	got, err := SplitArgs(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff(got, args)
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestProperties_LoadJavaOptions(t *testing.T) {
+	p := NewProperties()
+	rest, err := p.LoadJavaOptions(`-Xmx512m -Dfile.encoding=UTF-8 -Dapp.name='my app' -Dflag -D`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := cmp.Diff(rest, []string{"-Xmx512m", "-D"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(p.SystemArgs(), []string{"-Dapp.name=my app", "-Dfile.encoding=UTF-8", "-Dflag="})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(p.JavaOptions(), `'-Dapp.name=my app' -Dfile.encoding=UTF-8 -Dflag=`)
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	var version = p.Version()
+	p.Freeze()
+	if _, err = p.LoadArgs([]string{"-Dfile.encoding=ASCII", "-Dflag=1"}); err != ErrFrozen {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff([]interface{}{p.Version(), p.GetPropertyByDefault("file.encoding", "")}, []interface{}{version, "UTF-8"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
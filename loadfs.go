@@ -0,0 +1,86 @@
+package properties
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+// Loads the files of fsys matching the pattern, see fs.Glob, in lexical
+// order, so a later file overrides an earlier one, e.g. "conf.d/*.properties"
+// or a single "app.xml". Files with the extension XMLExt are read as XML
+// documents, other files as property files, processing include directives
+// (see IncludeKey). Directories are skipped, but at least one file must
+// match. Nothing is changed if any file fails to load.
+// The returned map records the file that supplied each key, the included
+// file for a key set by an include directive.
+func (p *Properties) LoadFS(fsys fs.FS, pattern string) (map[string]string, error) {
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var inc = fsIncluder(fsys)
+	var table = p.newHashtable()
+	var origin = make(map[string]string)
+	var found bool
+	for _, name := range names {
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+		found = true
+
+		var file = p.newHashtable()
+		var fileOrigin = make(map[string]string)
+		if strings.EqualFold(path.Ext(name), XMLExt) {
+			err = loadXMLFS(fsys, file, name)
+		} else {
+			err = p.include(inc, file, fileOrigin, name, nil)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range file.Keys() {
+			table.Put(key, file.Get(key))
+			if from, ok := fileOrigin[key.(string)]; ok {
+				origin[key.(string)] = from
+			} else {
+				origin[key.(string)] = name
+			}
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "open", Path: pattern, Err: fs.ErrNotExist}
+	}
+
+	if err = p.merge(table); err != nil {
+		return nil, err
+	}
+
+	return origin, nil
+}
+
+// Call p.LoadFS(os.DirFS(dir), pattern).
+func (p *Properties) LoadDir(dir, pattern string) (map[string]string, error) {
+	return p.LoadFS(os.DirFS(dir), pattern)
+}
+
+// Loads the XML document of fsys into table.
+func loadXMLFS(fsys fs.FS, table Hashtable, name string) error {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err = load(table, file); err != nil {
+		return &fs.PathError{Op: "load", Path: name, Err: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package properties
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestProperties_LoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conf.d/10-base.properties":  {Data: []byte("name = app\nlevel = info\n@include = common/*.properties\n")},
+		"conf.d/20-db.xml":           {Data: []byte(`<properties><entry key="db.host">localhost</entry><entry key="level">warn</entry></properties>`)},
+		"conf.d/30-local.properties": {Data: []byte("db.host = db.local\n")},
+		"conf.d/common/x.properties": {Data: []byte("common = 1\n")},
+		"conf.d/dir.properties/a":    {Data: []byte("ignored = 1\n")},
+		"bad/bad.xml":                {Data: []byte("<properties>")},
+	}
+
+	p := NewProperties()
+	p.SetProperty("keep", "1")
+	origin, err := p.LoadFS(fsys, "conf.d/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := cmp.Diff(p.ToMap(), map[interface{}]interface{}{
+		"keep": "1", "name": "app", "level": "warn", "common": "1", "db.host": "db.local",
+	})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(origin, map[string]string{
+		"name":    "conf.d/10-base.properties",
+		"common":  "conf.d/common/x.properties",
+		"level":   "conf.d/20-db.xml",
+		"db.host": "conf.d/30-local.properties",
+	})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	if _, err = p.LoadFS(fsys, "bad/*.xml"); err == nil {
+		t.Fatal("malformed XML loaded")
+	}
+	if _, err = p.LoadFS(fsys, "missing/*.properties"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatal("missing files loaded:", err)
+	}
+	if _, err = p.LoadFS(fsys, "["); err == nil {
+		t.Fatal("bad pattern accepted")
+	}
+	diff = cmp.Diff(p.Size(), 5)
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,238 @@
+package properties
+
+import (
+	"hash/fnv"
+	"reflect"
+)
+
+// A key and value pair of a property list.
+type Entry struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// Removes all of the properties of this property list,
+// the default property list is not affected.
+func (p *Properties) Clear() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.mustWritableLocked()
+	var changes []Change
+	p.Hashtable.Range(func(key, value interface{}) bool {
+		changes = appendChange(changes, key, value, nil)
+		return true
+	})
+	p.Hashtable.Clear()
+	p.commitLocked(changes)
+}
+
+// Tests if the key is in this property list,
+// not searching the default property list.
+func (p *Properties) ContainsKey(key interface{}) bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.Hashtable.ContainsKey(key)
+}
+
+// Tests if some key maps to the value in this property list,
+// not searching the default property list.
+func (p *Properties) ContainsValue(value interface{}) bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	var found bool
+	p.Hashtable.Range(func(key, v interface{}) bool {
+		found = reflect.DeepEqual(v, value)
+		return !found
+	})
+
+	return found
+}
+
+// Puts all of the entries of m in this property list atomically.
+func (p *Properties) PutAll(m map[interface{}]interface{}) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.mustWritableLocked()
+	var changes []Change
+	for key, value := range m {
+		changes = appendChange(changes, key, p.Hashtable.Put(key, value), value)
+	}
+	p.commitLocked(changes)
+}
+
+// Puts the value if the key is absent from this property list and returns
+// nil, otherwise returns the current value. The default property list is
+// not searched.
+func (p *Properties) PutIfAbsent(key, value interface{}) interface{} {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.mustWritableLocked()
+	if old := p.Hashtable.Get(key); old != nil {
+		return old
+	}
+	p.Hashtable.Put(key, value)
+	p.commitLocked(appendChange(nil, key, nil, value))
+
+	return nil
+}
+
+// Computes a new value of the key from its current value, nil if absent,
+// and returns it. If fn returns nil the key is removed.
+// fn is called under the write lock and must not access p.
+func (p *Properties) Compute(key interface{}, fn func(key, old interface{}) interface{}) interface{} {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.computeLocked(key, func(old interface{}) interface{} {
+		return fn(key, old)
+	})
+}
+
+// Puts the value if the key is absent, otherwise replaces the current value
+// with the result of fn, removing the key if fn returns nil. Returns the new
+// value. fn is called under the write lock and must not access p.
+func (p *Properties) Merge(key, value interface{}, fn func(old, value interface{}) interface{}) interface{} {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.computeLocked(key, func(old interface{}) interface{} {
+		if old == nil {
+			return value
+		}
+		return fn(old, value)
+	})
+}
+
+func (p *Properties) computeLocked(key interface{}, fn func(old interface{}) interface{}) interface{} {
+	p.mustWritableLocked()
+	var old = p.Hashtable.Get(key)
+	var value = fn(old)
+	if value == nil {
+		p.Hashtable.Remove(key)
+	} else {
+		p.Hashtable.Put(key, value)
+	}
+	p.commitLocked(appendChange(nil, key, old, value))
+
+	return value
+}
+
+// Replaces each value of this property list with the result of fn,
+// atomically, removing the keys for which fn returns nil, like Compute.
+// fn is called under the write lock and must not access p.
+func (p *Properties) ReplaceAll(fn func(key, value interface{}) interface{}) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.mustWritableLocked()
+	var changes []Change
+	for _, key := range p.Hashtable.Keys() {
+		var old = p.Hashtable.Get(key)
+		var value = fn(key, old)
+		if value == nil {
+			p.Hashtable.Remove(key)
+		} else {
+			p.Hashtable.Put(key, value)
+		}
+		changes = appendChange(changes, key, old, value)
+	}
+	p.commitLocked(changes)
+}
+
+// Calls fn for each entry of this property list, including entries whose
+// key or value is not a string, not including the default property list.
+// fn is called under the read lock and must not call any method of p, see Range.
+func (p *Properties) ForEach(fn func(key, value interface{})) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	p.Hashtable.Range(func(key, value interface{}) bool {
+		fn(key, value)
+		return true
+	})
+}
+
+// Returns the entries of this property list in iteration order,
+// not including the default property list.
+func (p *Properties) EntrySet() []Entry {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	var entries = make([]Entry, 0, p.Hashtable.Size())
+	p.Hashtable.Range(func(key, value interface{}) bool {
+		entries = append(entries, Entry{Key: key, Value: value})
+		return true
+	})
+
+	return entries
+}
+
+// Creates a shallow copy of this property list: the table is copied into a
+// hashtable of the same type, the keys and values themselves are not, and
+// the copy shares the default property list. Listeners, copy-on-write
+// mode, history and the frozen state are not copied.
+func (p *Properties) Clone() *Properties {
+	var clone = p.New()
+	clone.defaults.Store(p.defaults.Load())
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	p.Hashtable.Range(func(key, value interface{}) bool {
+		clone.Hashtable.Put(key, value)
+		return true
+	})
+
+	return clone
+}
+
+// Compares the entries of this property list with those of o, ignoring
+// iteration order and the default property lists, like the Java Map.equals.
+func (p *Properties) Equals(o *Properties) bool {
+	if p == o {
+		return true
+	}
+	if o == nil {
+		return false
+	}
+
+	var entries = p.EntrySet()
+	if len(entries) != o.Size() {
+		return false
+	}
+	for _, e := range entries {
+		if !reflect.DeepEqual(e.Value, o.Get(e.Key)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Returns the hash code of the entries of this property list, the sum of
+// the hash codes of the keys xor values, like the Java Map.hashCode.
+// Equal property lists have equal hash codes.
+func (p *Properties) HashCode() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	var code uint32
+	p.Hashtable.Range(func(key, value interface{}) bool {
+		code += hashOf(key) ^ hashOf(value)
+		return true
+	})
+
+	return int(int32(code))
+}
+
+func hashOf(v interface{}) uint32 {
+	var h = fnv.New32a()
+	_, _ = h.Write([]byte(stringOf(v)))
+
+	return h.Sum32()
+}
@@ -0,0 +1,104 @@
+package properties
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestProperties_MapAPI(t *testing.T) {
+	defaults := NewProperties()
+	defaults.SetProperty("d", "default")
+	p := NewProperties2()
+	if err := p.SetDefaults(defaults); err != nil {
+		t.Fatal(err)
+	}
+	p.PutAll(map[interface{}]interface{}{"a": "1"})
+	p.SetProperty("b", "2")
+
+	diff := cmp.Diff([]bool{p.ContainsKey("a"), p.ContainsKey("d"), p.ContainsValue("2"), p.ContainsValue("default")}, []bool{true, false, true, false})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff([]interface{}{p.PutIfAbsent("a", "x"), p.PutIfAbsent("c", "3")}, []interface{}{"1", nil})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	concat := func(old, value interface{}) interface{} { return old.(string) + value.(string) }
+	diff = cmp.Diff([]interface{}{p.Merge("a", "0", concat), p.Merge("e", "5", concat)}, []interface{}{"10", "5"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	p.Merge("e", "", func(old, value interface{}) interface{} { return nil })
+	diff = cmp.Diff(p.Compute("b", func(key, old interface{}) interface{} { return old.(string) + "!" }), interface{}("2!"))
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	p.Compute("c", func(key, old interface{}) interface{} { return nil })
+
+	diff = cmp.Diff(p.EntrySet(), []Entry{{"a", "10"}, {"b", "2!"}})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	clone := p.Clone()
+	diff = cmp.Diff([]interface{}{clone.Equals(&p.Properties), clone.HashCode() == p.HashCode(), clone.GetPropertyByDefault("d", "")}, []interface{}{true, true, "default"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	clone.ReplaceAll(func(key, value interface{}) interface{} { return key.(string) + "=" + value.(string) })
+	diff = cmp.Diff(clone.Keys(), []interface{}{"a", "b"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	var n int
+	clone.ForEach(func(key, value interface{}) {
+		if value != key.(string)+"="+p.Get(key).(string) {
+			t.Fatal("not replaced:", key, value)
+		}
+		n++
+	})
+	diff = cmp.Diff([]interface{}{n, clone.Equals(&p.Properties), clone.Equals(nil)}, []interface{}{2, false, false})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	removed := clone.Clone()
+	removed.ReplaceAll(func(key, value interface{}) interface{} {
+		if key == "a" {
+			return nil
+		}
+		return value
+	})
+	diff = cmp.Diff([]interface{}{removed.ContainsKey("a"), removed.Size()}, []interface{}{false, 1})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	diff = cmp.Diff([]interface{}{p.Remove("a"), p.Remove("a")}, []interface{}{"10", nil})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	p.Clear()
+	diff = cmp.Diff([]interface{}{p.Size(), p.GetPropertyByDefault("d", ""), clone.Size()}, []interface{}{0, "default", 2})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestHashtable_Clear(t *testing.T) {
+	for _, h := range []Hashtable{NewHashtable(), NewHashtable2(), NewSortedHashtable(nil), ToHashtable(NewTable[string, string]())} {
+		h.Put("a", "1")
+		h.Put("b", "2")
+		diff := cmp.Diff([]interface{}{h.Remove("a"), h.Remove("a"), h.ContainsKey("b"), h.ContainsKey("a")}, []interface{}{"1", nil, true, false})
+		if diff != "" {
+			t.Fatal(diff)
+		}
+		h.Clear()
+		h.Put("c", "3")
+		diff = cmp.Diff(h.Keys(), []interface{}{"c"})
+		if diff != "" {
+			t.Fatal(diff)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package properties
+
+import (
+	"bytes"
+	"io"
+)
+
+// A logical line of a property file: a comment or blank line, or an entry
+// with its continuation lines, kept as written.
+type rawUnit struct {
+	text  []byte
+	entry bool
+	key   string
+	value string
+}
+
+// A property file split into logical lines.
+type rawFile struct {
+	units []rawUnit
+	// The index of the last entry of each key, which is the effective one.
+	last map[string]int
+}
+
+// Splits a property file into logical lines, keeping their text.
+func parseRaw(data []byte) (*rawFile, error) {
+	var f = &rawFile{last: make(map[string]int)}
+	var parser = NewProperties()
+	for len(data) > 0 {
+		var n = lineLen(data)
+		var line = bytes.TrimLeft(bytes.TrimRight(data[:n], "\r\n"), " \t\f")
+		if len(line) == 0 || line[0] == '#' || line[0] == '!' {
+			f.units = append(f.units, rawUnit{text: data[:n]})
+			data = data[n:]
+			continue
+		}
+		for n < len(data) && continued(data[:n]) {
+			n += lineLen(data[n:])
+		}
+
+		var unit = rawUnit{text: data[:n], entry: true}
+		err := parser.load0(NewLineReader(bytes.NewReader(unit.text)), func(key, value string) error {
+			unit.key, unit.value = key, value
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		f.last[unit.key] = len(f.units)
+		f.units = append(f.units, unit)
+		data = data[n:]
+	}
+
+	return f, nil
+}
+
+// Returns the length of the first physical line, including its terminator.
+func lineLen(data []byte) int {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1
+	}
+
+	return len(data)
+}
+
+// Reports whether the physical lines continue on the next line,
+// that is whether they end with an odd number of backslashes.
+func continued(lines []byte) bool {
+	var line = bytes.TrimRight(lines, "\r\n")
+	var n int
+	for n < len(line) && line[len(line)-1-n] == '\\' {
+		n++
+	}
+
+	return n%2 == 1
+}
+
+// Returns the effective value of the key.
+func (f *rawFile) get(key string) (string, bool) {
+	if i, ok := f.last[key]; ok {
+		return f.units[i].value, true
+	}
+
+	return "", false
+}
+
+// Returns the text of the effective entry of the key with the comment
+// lines directly above it, nil if the key is absent.
+func (f *rawFile) entryWithComments(key string) []byte {
+	var i, ok = f.last[key]
+	if !ok {
+		return nil
+	}
+
+	var start = i
+	for start > 0 && !f.units[start-1].entry && len(bytes.TrimSpace(f.units[start-1].text)) > 0 {
+		start--
+	}
+	var text []byte
+	for _, u := range f.units[start : i+1] {
+		text = appendLine(text, u.text)
+	}
+
+	return text
+}
+
+// Appends line to text, terminating it if needed.
+func appendLine(text, line []byte) []byte {
+	text = append(text, line...)
+	if len(line) > 0 && line[len(line)-1] != '\n' {
+		text = append(text, '\n')
+	}
+
+	return text
+}
+
+// The outcome of a three-way merge of one key.
+type resolution int
+
+const (
+	keepOurs resolution = iota
+	takeTheirs
+	conflict
+)
+
+// Merges the property files ours and theirs, both derived from base, key by
+// key and writes the result to w, like a git merge driver. A key is taken
+// from the side that changed it from base; a key that both sides changed
+// differently is a conflict. The result is ours as written, with the entries
+// changed by theirs replaced in place, the entries removed by theirs
+// dropped, and the entries added by theirs, with the comment lines directly
+// above them, inserted after the entry that precedes them in theirs.
+// Each conflicting entry is written between conflict markers labeled with
+// oursLabel and theirsLabel, and its key returned in conflicts in the order
+// of the result. Entries are compared by their parsed key and value, so
+// reformatting or reordering an entry is not a change.
+func Merge3(w io.Writer, base, ours, theirs io.Reader, oursLabel, theirsLabel string) (conflicts []string, err error) {
+	var files [3]*rawFile
+	for i, r := range []io.Reader{base, ours, theirs} {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		if files[i], err = parseRaw(data); err != nil {
+			return nil, err
+		}
+	}
+	var b, o, t = files[0], files[1], files[2]
+
+	var resolve = func(key string) resolution {
+		bVal, bOK := b.get(key)
+		oVal, oOK := o.get(key)
+		tVal, tOK := t.get(key)
+		switch {
+		case oOK == tOK && oVal == tVal:
+			return keepOurs
+		case bOK == tOK && bVal == tVal:
+			return keepOurs
+		case bOK == oOK && bVal == oVal:
+			return takeTheirs
+		}
+		return conflict
+	}
+
+	var writeConflict = func(out []byte, key string) []byte {
+		conflicts = append(conflicts, key)
+		out = append(out, "<<<<<<< "+oursLabel+"\n"...)
+		if i, ok := o.last[key]; ok {
+			out = appendLine(out, o.units[i].text)
+		}
+		out = append(out, "=======\n"...)
+		if i, ok := t.last[key]; ok {
+			out = appendLine(out, t.units[i].text)
+		}
+		return append(out, ">>>>>>> "+theirsLabel+"\n"...)
+	}
+
+	// The keys only theirs has, by the key of the ours entry they follow,
+	// "" for the end of the file.
+	var inserts = make(map[string][]string)
+	var anchor string
+	for i, u := range t.units {
+		if !u.entry || t.last[u.key] != i {
+			continue
+		}
+		if _, ok := o.last[u.key]; ok {
+			anchor = u.key
+			continue
+		}
+		if resolve(u.key) != keepOurs {
+			inserts[anchor] = append(inserts[anchor], u.key)
+		}
+	}
+
+	var writeInserts = func(out []byte, anchor string) []byte {
+		for _, key := range inserts[anchor] {
+			if resolve(key) == conflict {
+				out = writeConflict(out, key)
+			} else {
+				out = appendLine(out, t.entryWithComments(key))
+			}
+		}
+		return out
+	}
+
+	var out []byte
+	for i, u := range o.units {
+		if !u.entry {
+			out = append(out, u.text...)
+			continue
+		}
+
+		var res = resolve(u.key)
+		if o.last[u.key] != i {
+			// A shadowed entry, kept unless theirs removed the key.
+			if _, ok := t.last[u.key]; ok || res != takeTheirs {
+				out = append(out, u.text...)
+			}
+			continue
+		}
+
+		switch res {
+		case keepOurs:
+			out = append(out, u.text...)
+		case takeTheirs:
+			if j, ok := t.last[u.key]; ok {
+				out = appendLine(out, t.units[j].text)
+			}
+		case conflict:
+			out = writeConflict(out, u.key)
+		}
+		if len(inserts[u.key]) > 0 {
+			if len(out) > 0 && out[len(out)-1] != '\n' {
+				out = append(out, '\n')
+			}
+			out = writeInserts(out, u.key)
+		}
+	}
+	if len(inserts[""]) > 0 {
+		if len(out) > 0 && out[len(out)-1] != '\n' {
+			out = append(out, '\n')
+		}
+		out = writeInserts(out, "")
+	}
+
+	if _, err = w.Write(out); err != nil {
+		return nil, err
+	}
+
+	return conflicts, nil
+}
@@ -0,0 +1,69 @@
+package properties
+
+import (
+	"bytes"
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	base := "# app\n" +
+		"name = app\n" +
+		"db.host = base\n" +
+		"db.port = 5432\n" +
+		"timeout = 30\n" +
+		"retries = 3\n"
+	ours := "# app\n" +
+		"name = app\n" +
+		"db.host: ours\n" +
+		"db.port = 5432\n" +
+		"timeout = 60\n" +
+		"retries=3\n" +
+		"ours.only = 1\n"
+	theirs := "retries = 3\n" +
+		"name = app\n" +
+		"db.port = 6543\n" +
+		"timeout = 90\n" +
+		"# the pool size\n" +
+		"pool = 10, \\\n" +
+		"       20\n" +
+		"db.host = ours\n"
+
+	var out bytes.Buffer
+	conflicts, err := Merge3(&out, strings.NewReader(base), strings.NewReader(ours), strings.NewReader(theirs), "HEAD", "feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := cmp.Diff(conflicts, []string{"timeout"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(out.String(), "# app\n"+
+		"name = app\n"+
+		"db.host: ours\n"+
+		"db.port = 6543\n"+
+		"<<<<<<< HEAD\n"+
+		"timeout = 60\n"+
+		"=======\n"+
+		"timeout = 90\n"+
+		">>>>>>> feature\n"+
+		"# the pool size\n"+
+		"pool = 10, \\\n"+
+		"       20\n"+
+		"retries=3\n"+
+		"ours.only = 1\n")
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	out.Reset()
+	conflicts, err = Merge3(&out, strings.NewReader("a = 1\nb = 1"), strings.NewReader("a = 1\nb = 1"), strings.NewReader("b = 1\nc = 1"), "ours", "theirs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff([]interface{}{conflicts, out.String()}, []interface{}{[]string(nil), "b = 1\nc = 1\n"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,180 @@
+package properties
+
+import (
+	"iter"
+	"sort"
+	"sync"
+)
+
+// A property list of one tenant: a small set of overrides over a base
+// property list shared by many tenants. The base is referenced, never
+// copied, so changes of the base are seen by every overlay at once, and an
+// overlay costs only its overrides. An overlay can also hide a key of the
+// base, see Remove. An Overlay is safe for concurrent use.
+type Overlay struct {
+	base *Properties
+
+	mutex     sync.RWMutex
+	overrides map[string]override
+}
+
+type override struct {
+	value   string
+	removed bool
+}
+
+// Creates an overlay with no overrides over the base.
+func NewOverlay(base *Properties) *Overlay {
+	return &Overlay{
+		base:      base,
+		overrides: make(map[string]override),
+	}
+}
+
+// Returns the base property list.
+func (o *Overlay) Base() *Properties {
+	return o.base
+}
+
+// Searches for the property in the overrides of this overlay and then
+// in the base, including its default property list.
+func (o *Overlay) GetProperty(key string) (string, bool) {
+	o.mutex.RLock()
+	ov, ok := o.overrides[key]
+	o.mutex.RUnlock()
+
+	if ok {
+		return ov.value, !ov.removed
+	}
+
+	return o.base.GetProperty(key)
+}
+
+// Returns the property value or defaultValue if the property is not found.
+func (o *Overlay) GetPropertyByDefault(key, defaultValue string) string {
+	if val, exist := o.GetProperty(key); exist {
+		return val
+	}
+
+	return defaultValue
+}
+
+// Overrides the property for this overlay, the base is not affected.
+func (o *Overlay) SetProperty(key, value string) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.overrides[key] = override{value: value}
+}
+
+// Hides the property of the base for this overlay,
+// the base is not affected.
+func (o *Overlay) Remove(key string) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.overrides[key] = override{removed: true}
+}
+
+// Drops the override of the property, set or removed, so the value of the
+// base applies again. Reports whether the property was overridden.
+func (o *Overlay) Reset(key string) bool {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	_, ok := o.overrides[key]
+	delete(o.overrides, key)
+
+	return ok
+}
+
+// Reports whether the property is overridden, set or removed, by this overlay.
+func (o *Overlay) IsOverridden(key string) bool {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	_, ok := o.overrides[key]
+
+	return ok
+}
+
+// Returns the keys overridden, set or removed, by this overlay in sorted order.
+func (o *Overlay) Overrides() []string {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	var keys = make([]string, 0, len(o.overrides))
+	for key := range o.overrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Calls fn for each effective property of this overlay, the properties of
+// the base that are not overridden and then the properties set by this
+// overlay, until fn returns false. Nothing is copied, but the read locks of
+// the overlay and the base are held during the iteration, so fn must not
+// call any method of either, see Properties.Range.
+func (o *Overlay) Range(fn func(key, value string) bool) {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	var done bool
+	o.base.RangeEffective(func(key, value string) bool {
+		if _, ok := o.overrides[key]; ok {
+			return true
+		}
+		done = !fn(key, value)
+		return !done
+	})
+	if done {
+		return
+	}
+	for key, ov := range o.overrides {
+		if !ov.removed && !fn(key, ov.value) {
+			return
+		}
+	}
+}
+
+// Returns an iterator over the effective properties of this overlay,
+// see Range.
+func (o *Overlay) All() iter.Seq2[string, string] {
+	return o.Range
+}
+
+// Returns the keys of the effective properties of this overlay.
+func (o *Overlay) StringPropertyNames() []string {
+	var keys []string
+	o.Range(func(key, value string) bool {
+		keys = append(keys, key)
+		return true
+	})
+
+	return keys
+}
+
+// Returns the effective properties of this overlay as a new map.
+func (o *Overlay) ToMap() map[string]string {
+	var m = make(map[string]string)
+	o.Range(func(key, value string) bool {
+		m[key] = value
+		return true
+	})
+
+	return m
+}
+
+// Returns a new property list, of the same type as the base, holding the
+// effective properties of this overlay, with no default property list.
+func (o *Overlay) Flatten() *Properties {
+	var flat = o.base.New()
+	o.Range(func(key, value string) bool {
+		flat.Hashtable.Put(key, value)
+		return true
+	})
+
+	return flat
+}
@@ -0,0 +1,74 @@
+package properties
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"sort"
+	"testing"
+)
+
+func TestOverlay(t *testing.T) {
+	defaults := NewProperties()
+	defaults.SetProperty("region", "eu")
+	base := NewPropertiesDefault(defaults)
+	base.SetProperty("theme", "light")
+	base.SetProperty("limit", "100")
+	base.SetProperty("beta", "false")
+
+	acme := NewOverlay(base)
+	acme.SetProperty("limit", "500")
+	acme.SetProperty("logo", "acme.png")
+	acme.Remove("beta")
+	other := NewOverlay(base)
+
+	base.SetProperty("theme", "dark")
+	diff := cmp.Diff(acme.ToMap(), map[string]string{"region": "eu", "theme": "dark", "limit": "500", "logo": "acme.png"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(other.ToMap(), map[string]string{"region": "eu", "theme": "dark", "limit": "100", "beta": "false"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(acme.Overrides(), []string{"beta", "limit", "logo"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	val, exist := acme.GetProperty("beta")
+	diff = cmp.Diff([]interface{}{val, exist, acme.GetPropertyByDefault("region", ""), acme.IsOverridden("theme")}, []interface{}{"", false, "eu", false})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	names := acme.StringPropertyNames()
+	sort.Strings(names)
+	diff = cmp.Diff(names, []string{"limit", "logo", "region", "theme"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	var n int
+	for range acme.All() {
+		n++
+		break
+	}
+	diff = cmp.Diff(n, 1)
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	diff = cmp.Diff([]bool{acme.Reset("beta"), acme.Reset("beta")}, []bool{true, false})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	flat := acme.Flatten()
+	diff = cmp.Diff([]interface{}{flat.ToMap(), len(flat.Defaults())}, []interface{}{
+		map[interface{}]interface{}{"region": "eu", "theme": "dark", "limit": "500", "logo": "acme.png", "beta": "false"}, 0,
+	})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(base.GetPropertyByDefault("limit", ""), "100")
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,64 @@
+package properties
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+)
+
+// Extension of property files.
+const PropertiesExt = ".properties"
+
+// Loads base.properties followed by base-{profile}.properties of each
+// active profile in order from fsys, so a later profile overrides an
+// earlier one, e.g. base "config/application" with profiles "dev", "dev-eu"
+// loads config/application.properties, config/application-dev.properties
+// and config/application-dev-eu.properties. Include directives are
+// processed (see IncludeKey).
+// Missing files are skipped, but at least one file must exist.
+// Nothing is changed if any file fails to load.
+// The returned map records the profile that supplied each key,
+// "" for the base file.
+func (p *Properties) LoadProfiles(fsys fs.FS, base string, profiles ...string) (map[string]string, error) {
+	var inc = fsIncluder(fsys)
+	var table = p.newHashtable()
+	var origin = make(map[string]string)
+	var found bool
+
+	for i, profile := range append([]string{""}, profiles...) {
+		if i > 0 && profile == "" {
+			continue
+		}
+		var name = path.Clean(base + PropertiesExt)
+		if profile != "" {
+			name = path.Clean(base + "-" + profile + PropertiesExt)
+		}
+		var file = p.newHashtable()
+		if err := p.include(inc, file, nil, name, nil); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		for _, key := range file.Keys() {
+			table.Put(key, file.Get(key))
+			origin[key.(string)] = profile
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "open", Path: base + "*" + PropertiesExt, Err: fs.ErrNotExist}
+	}
+
+	if err := p.merge(table); err != nil {
+		return nil, err
+	}
+
+	return origin, nil
+}
+
+// Call p.LoadProfiles(os.DirFS(dir), base, profiles...).
+func (p *Properties) LoadProfilesDir(dir, base string, profiles ...string) (map[string]string, error) {
+	return p.LoadProfiles(os.DirFS(dir), base, profiles...)
+}
@@ -0,0 +1,42 @@
+package properties
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"testing"
+	"testing/fstest"
+)
+
+func TestProperties_LoadProfiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/application.properties":        {Data: []byte("name = app\ndb.host = localhost\nlog.level = info\n")},
+		"config/application-dev.properties":    {Data: []byte("db.host = dev-db\nlog.level = debug\n")},
+		"config/application-dev-eu.properties": {Data: []byte("db.host = dev-eu-db\nregion = eu\n")},
+	}
+
+	p := NewProperties()
+	origin, err := p.LoadProfiles(fsys, "config/application", "dev", "staging", "dev-eu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := cmp.Diff(origin, map[string]string{
+		"name":      "",
+		"db.host":   "dev-eu",
+		"log.level": "dev",
+		"region":    "dev-eu",
+	})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(p.GetPropertyByDefault("db.host", ""), "dev-eu-db")
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(p.GetPropertyByDefault("log.level", ""), "debug")
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	if _, err = NewProperties().LoadProfiles(fsys, "config/missing", "dev"); err == nil {
+		t.Fatal("no error without any file")
+	}
+}
@@ -5,21 +5,54 @@ import (
 	"bytes"
 	"errors"
 	"io"
+	"iter"
 	"runtime"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // A table of hex digits
 var hexDigit = []byte{'0', '1', '2', '3', '4', '5', '6', '7', '8', '9', 'A', 'B', 'C', 'D', 'E', 'F'}
 
+// The Properties class represents a persistent set of properties.
+//
+// A Properties is safe for concurrent use. Every method goes through its
+// read-write mutex: readers (GetProperty, Get, Keys, Store, List, ...)
+// share the read lock and never block each other, writers (SetProperty,
+// Put, Remove, Load, Reload, ...) hold the write lock, so every write,
+// including a whole Load, is applied atomically and linearizable.
+// Lookups through the default property list release the lock of a property
+// list before locking its defaults, and RangeEffective locks one property
+// list at a time, so no method nests the locks of two property lists and a
+// defaults chain cannot deadlock.
+// The embedded Hashtable is safe for concurrent use on its own, but must
+// only be accessed through the Properties methods, which shadow it.
+// In copy-on-write mode (see SetCopyOnWrite) GetProperty reads an
+// immutable copy without locking.
 type Properties struct {
 	Hashtable
-	mutex sync.Mutex
+	mutex sync.RWMutex
 
-	// A property list that contains default values for any keys not
-	// found in this property list.
-	defaults *Properties
+	// The copy published in copy-on-write mode, nil otherwise.
+	published atomic.Pointer[Snapshot]
+
+	// The listeners of OnChange.
+	listeners []*listener
+
+	// The property lists that contain default values for any keys not
+	// found in this property list, in priority order. The slice is
+	// replaced, never modified, see SetDefaults.
+	defaults atomic.Pointer[[]*Properties]
+
+	// The number of writes that changed this property list.
+	version uint64
+	// The recorded revisions in history mode, nil otherwise.
+	history *history
+
+	// Whether writes fail, see Freeze.
+	frozen freezeMode
 }
 
 // Creates an empty property list with no default values.
@@ -27,14 +60,14 @@ func NewProperties() *Properties {
 	var hash = NewHashtable()
 	return &Properties{
 		Hashtable: hash,
-		defaults:  nil,
 	}
 }
 
-// Creates an empty property list with the specified defaults.
-func NewPropertiesDefault(defaults *Properties) *Properties {
+// Creates an empty property list with the specified defaults, searched in
+// order. Nil defaults are ignored.
+func NewPropertiesDefault(defaults ...*Properties) *Properties {
 	var properties = NewProperties()
-	properties.defaults = defaults
+	properties.setDefaults(defaults)
 
 	return properties
 }
@@ -43,22 +76,229 @@ func NewPropertiesDefault(defaults *Properties) *Properties {
 // Get method. Enforces use of strings for property keys and values.
 // The value returned is the result of the Hashtable call to Put.
 func (p *Properties) SetProperty(key, value string) interface{} {
+	return p.Put(key, value)
+}
+
+// Maps the key to the value in this property list and returns the previous value.
+func (p *Properties) Put(key, value interface{}) interface{} {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	return p.Put(key, value)
+	p.mustWritableLocked()
+	var old = p.Hashtable.Put(key, value)
+	p.commitLocked(appendChange(nil, key, old, value))
+
+	return old
+}
+
+// Returns the value of the key in this property list, not searching the
+// default property list.
+func (p *Properties) Get(key interface{}) interface{} {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.Hashtable.Get(key)
+}
+
+// Removes the key from this property list and returns its value, nil if it
+// was absent. The default property list is not affected.
+func (p *Properties) Remove(key interface{}) interface{} {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.mustWritableLocked()
+	var old = p.Hashtable.Remove(key)
+	p.commitLocked(appendChange(nil, key, old, nil))
+
+	return old
+}
+
+// Returns the number of keys in this property list, not counting the default property list.
+func (p *Properties) Size() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.Hashtable.Size()
+}
+
+// Returns the keys of this property list, not including the default property list.
+func (p *Properties) Keys() []interface{} {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.Hashtable.Keys()
+}
+
+// Calls fn for each property of this property list whose key and value are
+// strings, in the iteration order of the Hashtable, until fn returns false.
+// The default property list is not included. Nothing is allocated per
+// property, but the read lock is held during the iteration, so fn must
+// not call any method of p, not even to read: a writer waiting for the
+// lock blocks a nested read lock, and the iteration deadlocks. Iterate
+// Snapshot().ToMap() or StringPropertyNames instead when fn needs p.
+func (p *Properties) Range(fn func(key, value string) bool) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	p.Hashtable.Range(func(key, value interface{}) bool {
+		sKey, ok := key.(string)
+		if !ok {
+			return true
+		}
+		sVal, ok := value.(string)
+		if !ok {
+			return true
+		}
+		return fn(sKey, sVal)
+	})
+}
+
+// Returns an iterator over the string properties of this property list,
+// see Range: the loop body must not call any method of p.
+func (p *Properties) All() iter.Seq2[string, string] {
+	return p.Range
+}
+
+// Calls fn for each effective string property, the properties of this
+// property list followed by the properties of the default property lists,
+// recursively in search order, that are not shadowed by a key found before
+// them, until fn returns false. Unlike PropertyNames no value is copied,
+// only the keys seen are collected to detect shadowing. Each property list
+// is read locked while it is iterated, as by Range, so fn must not call any
+// method of p or its defaults.
+func (p *Properties) RangeEffective(fn func(key, value string) bool) {
+	p.rangeEffective(fn, make(map[string]bool), make(map[*Properties]bool))
+}
+
+// Returns an iterator over the effective string properties, see RangeEffective.
+func (p *Properties) Effective() iter.Seq2[string, string] {
+	return p.RangeEffective
+}
+
+// Calls fn for the properties whose keys are not in seen, the keys of the
+// property lists searched before, and adds the keys of p to seen. Returns
+// false if fn stopped. Only the lock of p is held, so the locks of two
+// property lists are never nested.
+func (p *Properties) rangeEffective(fn func(key, value string) bool, seen map[string]bool, visited map[*Properties]bool) bool {
+	if visited[p] {
+		return true
+	}
+	visited[p] = true
+
+	var more = true
+	p.Range(func(key, value string) bool {
+		if seen[key] {
+			return true
+		}
+		seen[key] = true
+		more = fn(key, value)
+		return more
+	})
+	if !more {
+		return false
+	}
+
+	for _, d := range p.parents() {
+		if !d.rangeEffective(fn, seen, visited) {
+			return false
+		}
+	}
+
+	return true
 }
 
 // The specified Reader remains open after this method returns.
 // reader the input character reader.
+// The input is parsed completely before any property is set, and the
+// properties are then set atomically, so nothing is changed if the input
+// is malformed and readers never observe a partially loaded input.
 func (p *Properties) Load(reader io.Reader) error {
+	table, err := p.parse(reader)
+	if err != nil {
+		return err
+	}
+	return p.merge(table)
+}
+
+// Replaces all of the properties of this table, not the default property
+// list, with the properties read from the input character stream.
+// Like Load, nothing is changed if the input is malformed, and readers
+// observe either the old or the new properties.
+func (p *Properties) Reload(reader io.Reader) error {
+	table, err := p.parse(reader)
+	if err != nil {
+		return err
+	}
+	_, err = p.replace(table)
+
+	return err
+}
+
+// Parses the input character stream into a new hashtable of the same type as this one.
+func (p *Properties) parse(reader io.Reader) (Hashtable, error) {
+	var table = p.newHashtable()
+	err := p.load0(NewLineReader(reader), func(key, value string) error {
+		table.Put(key, value)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return table, nil
+}
+
+// Sets all of the entries of table in this property list atomically.
+func (p *Properties) merge(table Hashtable) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	return p.load0(NewLineReader(reader))
+	if err := p.writableLocked(); err != nil {
+		return err
+	}
+
+	var changes []Change
+	table.Range(func(key, value interface{}) bool {
+		changes = appendChange(changes, key, p.Hashtable.Put(key, value), value)
+		return true
+	})
+	p.commitLocked(changes)
+
+	return nil
 }
 
-func (p *Properties) load0(lr *LineReader) error {
+// Replaces the hashtable of this property list atomically
+// and returns the changes, sorted by key.
+func (p *Properties) replace(table Hashtable) ([]Change, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err := p.writableLocked(); err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	p.Hashtable.Range(func(key, value interface{}) bool {
+		changes = appendChange(changes, key, value, table.Get(key))
+		return true
+	})
+	table.Range(func(key, value interface{}) bool {
+		if p.Hashtable.Get(key) == nil {
+			changes = appendChange(changes, key, nil, value)
+		}
+		return true
+	})
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	p.Hashtable = table
+	p.commitLocked(changes)
+
+	return changes, nil
+}
+
+// Parses the logical lines of lr and calls put with each key and element pair.
+func (p *Properties) load0(lr *LineReader, put func(key, value string) error) error {
 	var convertBuf = make([]byte, 4096)
 	var limit, keyLen, valueStart int
 	var c byte
@@ -110,7 +350,9 @@ func (p *Properties) load0(lr *LineReader) error {
 		if err != nil {
 			return err
 		}
-		p.Put(key, value)
+		if err = put(key, value); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -182,7 +424,7 @@ func (p *Properties) loadConvert(in []byte, off, length int, convertBuf []byte)
 
 // Converts unicode to encoded &#92;uxxxx and escapes
 // special characters with a preceding slash
-func (p *Properties) saveConvert(theString string, escapeSpace, escapeUnicode bool) string {
+func saveConvert(theString string, escapeSpace, escapeUnicode bool) string {
 	var length = len(theString)
 	var bufLen = length * 2
 	if bufLen < 0 {
@@ -256,11 +498,18 @@ func (p *Properties) Save(writer io.Writer, comments []byte) {
 // output character stream in a format suitable for using the io.Reader load(Reader)
 // After the entries have been written, the output stream is flushed.
 // The output stream remains open after this method returns.
+// Keys and values that are not strings are converted with ConvertValues.
 func (p *Properties) Store(writer io.Writer, comments []byte) error {
-	return p.store0(bufio.NewWriter(writer), comments, true)
+	return p.StoreByPolicy(writer, comments, ConvertValues)
 }
 
-func (p *Properties) store0(w io.Writer, comments []byte, escUnicode bool) (err error) {
+// Call p.Store(writer, comments), handling keys and values
+// that are not strings according to the policy.
+func (p *Properties) StoreByPolicy(writer io.Writer, comments []byte, policy ValuePolicy) error {
+	return p.store0(writer, comments, true, policy)
+}
+
+func (p *Properties) store0(w io.Writer, comments []byte, escUnicode bool, policy ValuePolicy) (err error) {
 	var bw = bufio.NewWriter(w)
 	if comments != nil {
 		if err = writeComments(bw, comments); err != nil {
@@ -274,19 +523,22 @@ func (p *Properties) store0(w io.Writer, comments []byte, escUnicode bool) (err
 		return err
 	}
 
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
-
-	for _, key := range p.Keys() {
-		var val = p.Get(key)
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
 
-		var sKey = key.(string)
-		var sVal = val.(string)
+	for _, key := range p.Hashtable.Keys() {
+		sKey, sVal, ok, err := policy.strings(key, p.Hashtable.Get(key))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
 
-		sKey = p.saveConvert(sKey, true, escUnicode)
+		sKey = saveConvert(sKey, true, escUnicode)
 		// No need to escape embedded and trailing spaces for value, hence
 		// pass false to flag.
-		sVal = p.saveConvert(sVal, false, escUnicode)
+		sVal = saveConvert(sVal, false, escUnicode)
 		if _, err = bw.WriteString(sKey + " = " + sVal); err != nil {
 			return err
 		}
@@ -303,11 +555,31 @@ func (p *Properties) store0(w io.Writer, comments []byte, escUnicode bool) (err
 // An implementation is required to read XML documents that use the
 // UTF-8 or UTF-16 encoding.
 // An implementation may support additional encodings.
+// Like Load, nothing is changed if the document is malformed, and the
+// properties are set atomically.
+// Like Load and the Java loadFromXML, the entries are added to the
+// existing properties. Earlier versions replaced all of the properties
+// with the document instead, use ReloadFromXML for that.
 func (p *Properties) LoadFromXML(reader io.Reader) error {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
+	var table = p.newHashtable()
+	if err := load(table, reader); err != nil {
+		return err
+	}
 
-	return load(p, reader)
+	return p.merge(table)
+}
+
+// Replaces all of the properties of this table, not the default property
+// list, with the properties represented by the XML document.
+// Like Reload, nothing is changed if the document is malformed.
+func (p *Properties) ReloadFromXML(reader io.Reader) error {
+	var table = p.newHashtable()
+	if err := load(table, reader); err != nil {
+		return err
+	}
+	_, err := p.replace(table)
+
+	return err
 }
 
 // Call p.StoreToXMLByEncoding(writer, comment, "UTF-8").
@@ -319,25 +591,31 @@ func (p *Properties) StoreToXML(writer io.Writer, comments []byte) error {
 // Emits an XML document representing all of the properties contained in this table.
 // An invocation of this method of the form p.StoreToXML(writer, comment)
 // behaves in exactly the same way as the invocation.
+// Keys and values that are not strings are converted with ConvertValues.
 func (p *Properties) StoreToXMLByEncoding(writer io.Writer, comments []byte, encoding string) error {
-	return save(p, writer, comments, encoding)
+	return p.StoreToXMLByPolicy(writer, comments, encoding, ConvertValues)
+}
+
+// Call p.StoreToXMLByEncoding(writer, comments, encoding), handling keys
+// and values that are not strings according to the policy.
+func (p *Properties) StoreToXMLByPolicy(writer io.Writer, comments []byte, encoding string, policy ValuePolicy) error {
+	return save(p, writer, comments, encoding, policy)
 }
 
 // Searches for the property with the specified key in this property list.
-// If the key is not found in this property list, the default property list,
-// and its defaults, recursively, are then checked. The method returns
+// If the key is not found in this property list, the default property lists,
+// and their defaults, recursively, are then checked in order. The method returns
 // Return "", false if the property is not found.
 func (p *Properties) GetProperty(key string) (val string, exist bool) {
-	var oval = p.Get(key)
-	if sVal, ok := oval.(string); ok {
+	if s := p.published.Load(); s != nil {
+		if sVal, ok := s.values[key]; ok {
+			return sVal, true
+		}
+	} else if sVal, ok := p.Get(key).(string); ok {
 		return sVal, true
 	}
 
-	if p.defaults != nil {
-		return p.defaults.GetProperty(key)
-	}
-
-	return "", false
+	return p.getDefault(key)
 }
 
 // Searches for the property with the specified key in this property list.
@@ -390,32 +668,51 @@ func (p *Properties) StringPropertyNames() []string {
 // Rather than use an anonymous inner class to share common code, this
 // method is duplicated in order to ensure that a non-1.1 compiler can
 // compile this file.
+// Keys and values that are not strings are converted with ConvertValues,
+// nothing is listed if one of them cannot be converted.
 func (p *Properties) List(out io.Writer) {
-	_, _ = out.Write(append([]byte("-- listing properties --"), newLine()...))
+	_ = p.ListByPolicy(out, ConvertValues)
+}
 
+// Call p.List(out), handling keys and values that are not strings
+// according to the policy. Nothing is written if an error is returned.
+func (p *Properties) ListByPolicy(out io.Writer, policy ValuePolicy) error {
 	var h = p.newHashtable()
 	p.enumerate(h)
+
+	var buf bytes.Buffer
+	buf.WriteString("-- listing properties --")
+	buf.Write(newLine())
 	for _, key := range h.Keys() {
-		var val = h.Get(key)
-		var sKey = key.(string)
-		var sVal = val.(string)
+		sKey, sVal, ok, err := policy.strings(key, h.Get(key))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
 
 		if len(sVal) > 40 {
 			sVal = string([]byte(sVal)[:37]) + "..."
 		}
-		_, _ = out.Write(append([]byte(sKey+" = "+sVal), newLine()...))
+		buf.WriteString(sKey + " = " + sVal)
+		buf.Write(newLine())
 	}
+	_, err := out.Write(buf.Bytes())
+
+	return err
 }
 
 // Enumerates all key/value pairs in the specified hashtable.
 func (p *Properties) enumerate(h Hashtable) {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
-
-	if p.defaults != nil {
-		p.defaults.enumerate(h)
+	var defaults = p.parents()
+	for i := len(defaults) - 1; i >= 0; i-- {
+		defaults[i].enumerate(h)
 	}
 
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
 	for _, key := range p.Hashtable.Keys() {
 		h.Put(key, p.Hashtable.Get(key))
 	}
@@ -424,13 +721,14 @@ func (p *Properties) enumerate(h Hashtable) {
 // Enumerates all key/value pairs in the specified hashtable
 // and omits the property if the key or value is not a string.
 func (p *Properties) enumerateStringProperties(h Hashtable) {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
-
-	if p.defaults != nil {
-		p.defaults.enumerateStringProperties(h)
+	var defaults = p.parents()
+	for i := len(defaults) - 1; i >= 0; i-- {
+		defaults[i].enumerateStringProperties(h)
 	}
 
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
 	// safe assert type string
 	for _, key := range p.Hashtable.Keys() {
 		var val = p.Hashtable.Get(key)
@@ -444,15 +742,21 @@ func (p *Properties) enumerateStringProperties(h Hashtable) {
 
 // Create a hashtable that is the same type as itself.
 func (p *Properties) newHashtable() Hashtable {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
 	return p.Hashtable.New()
 }
 
 // Properties to map.
 func (p *Properties) ToMap() map[interface{}]interface{} {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
 	var m = make(map[interface{}]interface{})
-	keys := p.Keys()
+	keys := p.Hashtable.Keys()
 	for _, key := range keys {
-		m[key] = p.Get(key)
+		m[key] = p.Hashtable.Get(key)
 	}
 
 	return m
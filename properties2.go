@@ -2,27 +2,48 @@ package properties
 
 import (
 	"container/list"
+	"iter"
 	"sync"
 )
 
+// Position of a key put again into an insertion ordered hashtable.
+type Ordering int
+
+const (
+	// The key keeps the position of its first insertion.
+	KeepOrder Ordering = iota
+	// The key moves to the end, as if it was removed and inserted again.
+	MoveToEnd
+)
+
 // sequence hashtable
 type sequenceTable struct {
-	mutex   sync.Mutex
-	mapper  map[interface{}]interface{}
-	element map[interface{}]*list.Element
-	list    *list.List
+	mutex    sync.RWMutex
+	ordering Ordering
+	mapper   map[interface{}]interface{}
+	element  map[interface{}]*list.Element
+	list     *list.List
 }
 
+// Creates an empty hashtable iterated in insertion order,
+// a key put again keeps its position.
 func NewHashtable2() Hashtable {
+	return NewHashtable2Ordering(KeepOrder)
+}
+
+// Creates an empty hashtable iterated in insertion order,
+// the ordering decides the position of a key put again.
+func NewHashtable2Ordering(ordering Ordering) Hashtable {
 	return &sequenceTable{
-		mapper:  map[interface{}]interface{}{},
-		element: map[interface{}]*list.Element{},
-		list:    list.New(),
+		ordering: ordering,
+		mapper:   map[interface{}]interface{}{},
+		element:  map[interface{}]*list.Element{},
+		list:     list.New(),
 	}
 }
 
 func (s *sequenceTable) New() Hashtable {
-	return NewHashtable2()
+	return NewHashtable2Ordering(s.ordering)
 }
 
 func (s *sequenceTable) Put(key, value interface{}) interface{} {
@@ -31,37 +52,66 @@ func (s *sequenceTable) Put(key, value interface{}) interface{} {
 
 	old := s.mapper[key]
 	s.mapper[key] = value
-	s.element[key] = s.list.PushBack(key)
+	if e, ok := s.element[key]; !ok {
+		s.element[key] = s.list.PushBack(key)
+	} else if s.ordering == MoveToEnd {
+		s.list.MoveToBack(e)
+	}
 
 	return old
 }
 
 func (s *sequenceTable) Get(key interface{}) interface{} {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 
 	return s.mapper[key]
 }
 
-func (s *sequenceTable) Remove(key interface{}) {
+func (s *sequenceTable) Remove(key interface{}) interface{} {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	e, ok := s.element[key]
+	if !ok {
+		return nil
+	}
+	old := s.mapper[key]
 	delete(s.mapper, key)
-	s.list.Remove(s.element[key])
+	s.list.Remove(e)
 	delete(s.element, key)
+
+	return old
 }
 
-func (s *sequenceTable) Size() int {
+func (s *sequenceTable) Clear() {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	s.mapper = map[interface{}]interface{}{}
+	s.element = map[interface{}]*list.Element{}
+	s.list.Init()
+}
+
+func (s *sequenceTable) ContainsKey(key interface{}) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	_, ok := s.element[key]
+
+	return ok
+}
+
+func (s *sequenceTable) Size() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
 	return s.list.Len()
 }
 
 func (s *sequenceTable) Keys() []interface{} {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 
 	var keys = make([]interface{}, 0, s.list.Len())
 	for node := s.list.Front(); node != nil; node = node.Next() {
@@ -71,16 +121,38 @@ func (s *sequenceTable) Keys() []interface{} {
 	return keys
 }
 
+func (s *sequenceTable) Range(fn func(key, value interface{}) bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for node := s.list.Front(); node != nil; node = node.Next() {
+		if !fn(node.Value, s.mapper[node.Value]) {
+			return
+		}
+	}
+}
+
+func (s *sequenceTable) All() iter.Seq2[interface{}, interface{}] {
+	return s.Range
+}
+
 // sequence properties
 type Properties2 struct {
 	Properties
 }
 
+// Creates an empty property list iterated in insertion order,
+// a key set again keeps its position.
 func NewProperties2() *Properties2 {
-	properties := NewProperties()
-	properties.Hashtable = NewHashtable2()
+	return NewProperties2Ordering(KeepOrder)
+}
 
+// Creates an empty property list iterated in insertion order,
+// the ordering decides the position of a key set again.
+func NewProperties2Ordering(ordering Ordering) *Properties2 {
 	return &Properties2{
-		Properties: *properties,
+		Properties: Properties{
+			Hashtable: NewHashtable2Ordering(ordering),
+		},
 	}
 }
@@ -2,6 +2,7 @@ package properties
 
 import (
 	"github.com/google/go-cmp/cmp"
+	"strings"
 	"testing"
 )
 
@@ -46,3 +47,82 @@ func TestNewProperties2(t *testing.T) {
 		t.Fatal(diff)
 	}
 }
+
+func TestHashtable2Ordering(t *testing.T) {
+	tests := []struct {
+		ordering Ordering
+		keys     []interface{}
+	}{
+		{KeepOrder, []interface{}{"k1", "k2", "k3", "k5"}},
+		{MoveToEnd, []interface{}{"k3", "k1", "k2", "k5"}},
+	}
+	for _, test := range tests {
+		table := NewHashtable2Ordering(test.ordering)
+		table.Put("k1", "v1")
+		table.Put("k2", "v2")
+		table.Put("k3", "v3")
+		table.Put("k4", "v4")
+		diff := cmp.Diff(table.Put("k1", "v1.1"), "v1")
+		if diff != "" {
+			t.Fatal(diff)
+		}
+		table.Put("k2", "v2.1")
+		table.Remove("k4")
+		table.Remove("k4")
+		table.Remove("missing")
+		table.Put("k5", "v5")
+
+		diff = cmp.Diff(table.Keys(), test.keys)
+		if diff != "" {
+			t.Fatal(test.ordering, diff)
+		}
+		diff = cmp.Diff(table.Size(), len(test.keys))
+		if diff != "" {
+			t.Fatal(test.ordering, diff)
+		}
+		diff = cmp.Diff([]interface{}{table.Get("k1"), table.Get("k2"), table.Get("k4")}, []interface{}{"v1.1", "v2.1", nil})
+		if diff != "" {
+			t.Fatal(test.ordering, diff)
+		}
+
+		// New keeps the ordering policy.
+		table = table.New()
+		table.Put("a", "")
+		table.Put("b", "")
+		table.Put("a", "")
+		want := []interface{}{"a", "b"}
+		if test.ordering == MoveToEnd {
+			want = []interface{}{"b", "a"}
+		}
+		diff = cmp.Diff(table.Keys(), want)
+		if diff != "" {
+			t.Fatal(test.ordering, diff)
+		}
+	}
+}
+
+func TestProperties2_Store(t *testing.T) {
+	prop := NewProperties2()
+	prop.SetProperty("k1", "v1")
+	prop.SetProperty("k2", "v2")
+	prop.SetProperty("k1", "v3")
+
+	var buf strings.Builder
+	if err := prop.Store(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	diff := cmp.Diff(lines[1:], []string{"k1 = v3", "k2 = v2"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	loaded := NewProperties2Ordering(MoveToEnd)
+	if err := loaded.Load(strings.NewReader(buf.String() + "k1 = v4\n")); err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff(loaded.Keys(), []interface{}{"k2", "k1"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -3,9 +3,13 @@ package properties
 import (
 	"fmt"
 	"github.com/google/go-cmp/cmp"
+	"io"
 	"os"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestNewLineReader(t *testing.T) {
@@ -118,3 +122,249 @@ func TestProperties_LoadFromXML(t *testing.T) {
 
 	p.Store(os.Stdout, []byte("-----------comment-----------"))
 }
+
+func TestProperties_LoadAtomic(t *testing.T) {
+	var p = NewProperties()
+	p.SetProperty("a", "0")
+	if err := p.Load(strings.NewReader("a = 1\nb = \\uZZZZ\nc = 3\n")); err == nil {
+		t.Fatal("malformed input accepted")
+	}
+	diff := cmp.Diff(p.ToMap(), map[interface{}]interface{}{"a": "0"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	if err := p.LoadFromXML(strings.NewReader(`<properties><entry key="a">1</entry>`)); err == nil {
+		t.Fatal("malformed document accepted")
+	}
+	diff = cmp.Diff(p.ToMap(), map[interface{}]interface{}{"a": "0"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	if err := p.Load(strings.NewReader("b = 2\n")); err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff(p.ToMap(), map[interface{}]interface{}{"a": "0", "b": "2"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	if err := p.LoadFromXML(strings.NewReader(`<properties><entry key="c">3</entry></properties>`)); err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff(p.ToMap(), map[interface{}]interface{}{"a": "0", "b": "2", "c": "3"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestProperties_Reload(t *testing.T) {
+	var p = NewProperties2()
+	p.SetProperty("a", "0")
+	if err := p.Reload(strings.NewReader("c = 3\nb = 2\n")); err != nil {
+		t.Fatal(err)
+	}
+	diff := cmp.Diff(p.Keys(), []interface{}{"c", "b"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	if err := p.Reload(strings.NewReader("x = \\u00")); err == nil {
+		t.Fatal("malformed input accepted")
+	}
+	if err := p.ReloadFromXML(strings.NewReader(`<properties><entry key="d">4</entry></properties>`)); err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff(p.ToMap(), map[interface{}]interface{}{"d": "4"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestProperties_Concurrency(t *testing.T) {
+	var root = NewProperties()
+	root.SetProperty("root", "0")
+	var defaults = NewPropertiesDefault(root)
+	defaults.SetProperty("defaults", "0")
+
+	for _, p := range []*Properties{NewPropertiesDefault(defaults), &NewProperties2().Properties, NewSortedProperties(nil)} {
+		var wg sync.WaitGroup
+		var stop = make(chan struct{})
+		var errs = make(chan error, 64)
+
+		// writers
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for n := 0; n < 200; n++ {
+					key := fmt.Sprintf("k%d", n%10)
+					switch n % 5 {
+					case 0:
+						p.SetProperty(key, fmt.Sprint(n))
+					case 1:
+						p.Remove(key)
+					case 2:
+						_ = p.Load(strings.NewReader(fmt.Sprintf("x = %d\ny = %d\n", n, n)))
+					case 3:
+						_ = p.Reload(strings.NewReader(fmt.Sprintf("x = %d\ny = %d\n", n, n)))
+					case 4:
+						root.SetProperty("root", fmt.Sprint(n))
+						defaults.SetProperty(key, fmt.Sprint(n))
+					}
+				}
+			}(i)
+		}
+
+		// readers
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+					p.GetProperty("x")
+					p.GetPropertyByDefault("root", "")
+					p.PropertyNames()
+					p.StringPropertyNames()
+					p.List(io.Discard)
+					if err := p.Store(io.Discard, nil); err != nil {
+						errs <- err
+						return
+					}
+					if err := p.StoreToXML(io.Discard, nil); err != nil {
+						errs <- err
+						return
+					}
+					m := p.ToMap()
+					if x, y := m["x"], m["y"]; x != y {
+						errs <- fmt.Errorf("inconsistent load: x = %v, y = %v", x, y)
+						return
+					}
+				}
+			}()
+		}
+
+		go func() {
+			defer close(stop)
+			time.Sleep(50 * time.Millisecond)
+		}()
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestProperties_RangeEffectiveConcurrency(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	a := NewProperties()
+	b := NewProperties()
+	p := NewPropertiesDefault(a, b)
+	r := NewPropertiesDefault(b, a)
+	for i := 0; i < 10; i++ {
+		a.SetProperty(fmt.Sprint("k", i), "a")
+		b.SetProperty(fmt.Sprint("k", i), "b")
+	}
+
+	var wg sync.WaitGroup
+	for _, l := range []*Properties{a, b} {
+		wg.Add(1)
+		go func(l *Properties) {
+			defer wg.Done()
+			for n := 0; n < 20000; n++ {
+				l.SetProperty(fmt.Sprint("k", n%10), fmt.Sprint(n))
+			}
+		}(l)
+	}
+	for _, l := range []*Properties{p, r} {
+		wg.Add(1)
+		go func(l *Properties) {
+			defer wg.Done()
+			for n := 0; n < 20000; n++ {
+				var count int
+				for range l.Effective() {
+					count++
+				}
+				if count != 10 {
+					t.Errorf("effective properties: %d", count)
+					return
+				}
+			}
+		}(l)
+	}
+
+	var done = make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatal("RangeEffective deadlocked")
+	}
+}
+
+func TestProperties_Range(t *testing.T) {
+	var root = NewProperties2()
+	root.SetProperty("a", "root")
+	root.SetProperty("d", "root")
+	var defaults = NewPropertiesDefault(&root.Properties)
+	defaults.SetProperty("b", "defaults")
+	defaults.SetProperty("d", "defaults")
+	var p = NewProperties2()
+	if err := p.SetDefaults(defaults); err != nil {
+		t.Fatal(err)
+	}
+	p.SetProperty("c", "p")
+	p.Put("n", 1)
+	p.SetProperty("a", "p")
+
+	var got []string
+	p.Range(func(key, value string) bool {
+		got = append(got, key+"="+value)
+		return true
+	})
+	diff := cmp.Diff(got, []string{"c=p", "a=p"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	got = nil
+	for key, value := range p.Effective() {
+		got = append(got, key+"="+value)
+	}
+	diff = cmp.Diff(got[:2], []string{"c=p", "a=p"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	var effective = map[string]string{}
+	for _, kv := range got {
+		i := strings.Index(kv, "=")
+		if _, ok := effective[kv[:i]]; ok {
+			t.Fatal("duplicate key", kv)
+		}
+		effective[kv[:i]] = kv[i+1:]
+	}
+	diff = cmp.Diff(effective, map[string]string{"a": "p", "b": "defaults", "c": "p", "d": "defaults"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	got = nil
+	for key := range p.Effective() {
+		got = append(got, key)
+		if len(got) == 3 {
+			break
+		}
+	}
+	diff = cmp.Diff(len(got), 3)
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,278 @@
+package properties
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Options of SaveFile. The zero value saves without backups or locking.
+type SaveOptions struct {
+	// The comments written at the top of the file, see Store.
+	Comments []byte
+	// The permission bits of a new file, 0644 if 0. An existing file keeps
+	// its mode and, where permitted, its owner and group.
+	Perm os.FileMode
+	// The number of previous versions kept as name.1 (the latest) to
+	// name.N, 0 for none.
+	Backups int
+	// Takes an exclusive advisory lock on name.lock while saving, so
+	// processes saving the same file with Lock do not interleave.
+	// The lock does not cover a load that preceded the save, so a
+	// read-modify-write must use EditFile not to lose the concurrent changes.
+	// Not supported on every platform.
+	Lock bool
+}
+
+// Saves this property list to the file with the specified name, in XML if
+// its extension is XMLExt, atomically and durably: the properties are
+// written to a temporary file in the same directory, synced, and renamed
+// over the file, so readers see either the old or the new file, also after
+// a crash. If name is a symbolic link, the file it points to is replaced,
+// and the backups and the lock file are kept next to that file.
+// Nothing is changed, backups included, if the save fails; only an error
+// syncing the directory after the rename leaves the new file in place.
+func (p *Properties) SaveFile(name string, opts *SaveOptions) error {
+	var o = saveOptions(opts)
+	name, err := resolveLink(name)
+	if err != nil {
+		return err
+	}
+
+	if o.Lock {
+		unlock, err := lockFile(name + ".lock")
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	return p.saveFile(name, o)
+}
+
+// Loads the property file with the specified name, in XML if its extension
+// is XMLExt, into a new property list, empty if the file does not exist,
+// calls fn with it and, unless fn returns an error, saves it as by SaveFile.
+// The lock of SaveOptions.Lock is taken, whatever opts says, and held from
+// the load to the save, so edits of the same file by processes using
+// EditFile, or SaveFile with Lock, are not lost.
+func EditFile(name string, opts *SaveOptions, fn func(p *Properties) error) error {
+	var o = saveOptions(opts)
+	name, err := resolveLink(name)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockFile(name + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	var p = NewProperties()
+	file, err := os.Open(name)
+	switch {
+	case err == nil:
+		if strings.EqualFold(filepath.Ext(name), XMLExt) {
+			err = p.LoadFromXML(file)
+		} else {
+			err = p.Load(file)
+		}
+		_ = file.Close()
+		if err != nil {
+			return &os.PathError{Op: "load", Path: name, Err: err}
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	if err = fn(p); err != nil {
+		return err
+	}
+
+	return p.saveFile(name, o)
+}
+
+// Returns the options with the defaults applied.
+func saveOptions(opts *SaveOptions) SaveOptions {
+	var o SaveOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Perm == 0 {
+		o.Perm = 0644
+	}
+
+	return o
+}
+
+// Returns the file the symbolic link name points to,
+// or name if it is not a link or does not exist.
+func resolveLink(name string) (string, error) {
+	target, err := filepath.EvalSymlinks(name)
+	if err == nil {
+		return target, nil
+	}
+	if os.IsNotExist(err) {
+		return name, nil
+	}
+
+	return "", err
+}
+
+// Does the work of SaveFile on the resolved name,
+// the caller holds the lock if any.
+func (p *Properties) saveFile(name string, o SaveOptions) (err error) {
+	var dir, base = filepath.Split(name)
+	tmp, err := os.CreateTemp(dir, "."+base+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if strings.EqualFold(filepath.Ext(name), XMLExt) {
+		err = p.StoreToXML(tmp, o.Comments)
+	} else {
+		err = p.Store(tmp, o.Comments)
+	}
+	if err != nil {
+		return err
+	}
+
+	info, statErr := os.Stat(name)
+	switch {
+	case statErr == nil:
+		if err = tmp.Chmod(info.Mode().Perm()); err != nil {
+			return err
+		}
+		if err = chownLike(tmp, info); err != nil {
+			return err
+		}
+	case os.IsNotExist(statErr):
+		info = nil
+		if err = tmp.Chmod(o.Perm); err != nil {
+			return err
+		}
+	default:
+		return statErr
+	}
+
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	var rot = &rotation{}
+	if info != nil && o.Backups > 0 {
+		if err = rot.backup(name, o.Backups); err != nil {
+			rot.undo()
+			return err
+		}
+	}
+	if err = os.Rename(tmp.Name(), name); err != nil {
+		rot.undo()
+		return err
+	}
+	rot.commit()
+
+	return syncDir(dir)
+}
+
+// The steps of a backup rotation, so it can be undone
+// if the file cannot be replaced.
+type rotation struct {
+	// The renames done, in order.
+	renames [][2]string
+	// The oldest backup moved aside, removed on commit.
+	stash string
+	// The backup of the file created.
+	created string
+}
+
+// Rotates the backups name.1 to name.n and keeps the file as name.1.
+func (r *rotation) backup(name string, n int) error {
+	var oldest = name + "." + strconv.Itoa(n)
+	if _, err := os.Lstat(oldest); err == nil {
+		r.stash = oldest + ".old"
+		if err = r.rename(oldest, r.stash); err != nil {
+			r.stash = ""
+			return err
+		}
+	}
+	for i := n - 1; i > 0; i-- {
+		var older = name + "." + strconv.Itoa(i)
+		if err := r.rename(older, name+"."+strconv.Itoa(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	var latest = name + ".1"
+	if err := os.Link(name, latest); err != nil {
+		if err = copyFile(name, latest); err != nil {
+			return err
+		}
+	}
+	r.created = latest
+
+	return nil
+}
+
+func (r *rotation) rename(from, to string) error {
+	if err := os.Rename(from, to); err != nil {
+		return err
+	}
+	r.renames = append(r.renames, [2]string{from, to})
+
+	return nil
+}
+
+// Restores the backups as they were before the rotation.
+func (r *rotation) undo() {
+	if r.created != "" {
+		_ = os.Remove(r.created)
+	}
+	for i := len(r.renames) - 1; i >= 0; i-- {
+		_ = os.Rename(r.renames[i][1], r.renames[i][0])
+	}
+}
+
+// Drops the oldest backup moved aside by the rotation.
+func (r *rotation) commit() {
+	if r.stash != "" {
+		_ = os.Remove(r.stash)
+	}
+}
+
+// Copies the file src to dst, which is created with the mode of src.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		_ = out.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,20 @@
+//go:build !unix
+
+package properties
+
+import (
+	"errors"
+	"os"
+)
+
+func chownLike(file *os.File, info os.FileInfo) error {
+	return nil
+}
+
+func syncDir(dir string) error {
+	return nil
+}
+
+func lockFile(name string) (unlock func(), err error) {
+	return nil, &os.PathError{Op: "flock", Path: name, Err: errors.ErrUnsupported}
+}
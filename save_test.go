@@ -0,0 +1,182 @@
+package properties
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestProperties_SaveFile(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.properties")
+	load := func(name string) map[interface{}]interface{} {
+		p := NewProperties()
+		file, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		if err = p.Load(file); err != nil {
+			t.Fatal(err)
+		}
+		return p.ToMap()
+	}
+
+	p := NewProperties()
+	opts := &SaveOptions{Backups: 2, Lock: true}
+	for _, v := range []string{"1", "2", "3", "4"} {
+		p.SetProperty("v", v)
+		if err := p.SaveFile(name, opts); err != nil {
+			t.Fatal(err)
+		}
+		if v == "1" {
+			if err := os.Chmod(name, 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := cmp.Diff([]interface{}{load(name), load(name + ".1"), load(name + ".2"), info.Mode().Perm()}, []interface{}{
+		map[interface{}]interface{}{"v": "4"},
+		map[interface{}]interface{}{"v": "3"},
+		map[interface{}]interface{}{"v": "2"},
+		os.FileMode(0600),
+	})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	if _, err = os.Stat(name + ".3"); !os.IsNotExist(err) {
+		t.Fatal("too many backups:", err)
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, ".app.properties.tmp*"))
+	if err != nil || len(matches) != 0 {
+		t.Fatal("temporary files left:", matches, err)
+	}
+
+	xmlName := filepath.Join(dir, "app.xml")
+	if err = p.SaveFile(xmlName, nil); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(xmlName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	x := NewProperties()
+	if err = x.LoadFromXML(file); err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff(x.ToMap(), map[interface{}]interface{}{"v": "4"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	if err = p.SaveFile(filepath.Join(dir, "missing", "app.properties"), nil); err == nil {
+		t.Fatal("saved into a missing directory")
+	}
+
+	link := filepath.Join(dir, "link.properties")
+	if err = os.Symlink(name, link); err != nil {
+		t.Fatal(err)
+	}
+	p.SetProperty("v", "5")
+	if err = p.SaveFile(link, &SaveOptions{Backups: 2}); err != nil {
+		t.Fatal(err)
+	}
+	target, err := os.Readlink(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff([]interface{}{target, load(name), load(name + ".1"), load(name + ".2")}, []interface{}{
+		name,
+		map[interface{}]interface{}{"v": "5"},
+		map[interface{}]interface{}{"v": "4"},
+		map[interface{}]interface{}{"v": "3"},
+	})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	// A directory cannot be backed up, the backups must be left as they were.
+	conf := filepath.Join(dir, "conf")
+	if err = os.Mkdir(conf, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(conf+".1", []byte("v = old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = p.SaveFile(conf, &SaveOptions{Backups: 2}); err == nil {
+		t.Fatal("saved over a directory")
+	}
+	diff = cmp.Diff(load(conf+".1"), map[interface{}]interface{}{"v": "old"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	for _, n := range []string{conf + ".2", conf + ".1.old", conf + ".2.old"} {
+		if _, err = os.Lstat(n); !os.IsNotExist(err) {
+			t.Fatal("backups changed:", n, err)
+		}
+	}
+}
+
+func TestEditFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "app.properties")
+	increment := func(p *Properties) error {
+		n, _ := strconv.Atoi(p.GetPropertyByDefault("count", "0"))
+		p.SetProperty("count", strconv.Itoa(n+1))
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := EditFile(name, nil, increment); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var count string
+	if err := EditFile(name, nil, func(p *Properties) error {
+		count = p.GetPropertyByDefault("count", "")
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	diff := cmp.Diff(count, "20")
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	var errAbort = errors.New("abort")
+	if err := EditFile(name, nil, func(p *Properties) error {
+		p.SetProperty("count", "0")
+		return errAbort
+	}); err != errAbort {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewProperties()
+	if err = p.Load(strings.NewReader(string(data))); err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff(p.GetPropertyByDefault("count", ""), "20")
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,143 @@
+package properties
+
+import (
+	"sort"
+)
+
+// An immutable view of a property list and its default property list at
+// one point in time. A Snapshot is safe for concurrent use without locking,
+// so callers can hold a consistent view across many lookups.
+type Snapshot struct {
+	values   map[string]string
+	defaults []*Snapshot
+}
+
+// Returns a snapshot of the string properties of this property list and,
+// recursively, of its default property list. Later changes are not
+// reflected in the snapshot.
+// In copy-on-write mode this does not copy this property list.
+func (p *Properties) Snapshot() *Snapshot {
+	var s = p.published.Load()
+	if s == nil {
+		p.mutex.RLock()
+		s = p.snapshotLocked()
+		p.mutex.RUnlock()
+	}
+	var parents = p.parents()
+	if len(parents) == 0 {
+		return s
+	}
+
+	var defaults = make([]*Snapshot, len(parents))
+	for i, d := range parents {
+		defaults[i] = d.Snapshot()
+	}
+
+	return &Snapshot{
+		values:   s.values,
+		defaults: defaults,
+	}
+}
+
+// Enables or disables copy-on-write mode. In copy-on-write mode every
+// write publishes a new immutable copy of this property list through an
+// atomic pointer, so GetProperty and Snapshot never lock, at the cost of
+// copying the property list on each write. It suits property lists that
+// are read far more often than written.
+func (p *Properties) SetCopyOnWrite(enabled bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if enabled {
+		p.published.Store(p.snapshotLocked())
+	} else {
+		p.published.Store(nil)
+	}
+}
+
+// Reports whether copy-on-write mode is enabled.
+func (p *Properties) CopyOnWrite() bool {
+	return p.published.Load() != nil
+}
+
+// Publishes a new copy after a write in copy-on-write mode,
+// the caller holds the write lock.
+func (p *Properties) publishLocked() {
+	if p.published.Load() != nil {
+		p.published.Store(p.snapshotLocked())
+	}
+}
+
+// Copies the string properties of this table, not including the default
+// property list, the caller holds the lock.
+func (p *Properties) snapshotLocked() *Snapshot {
+	var keys = p.Hashtable.Keys()
+	var values = make(map[string]string, len(keys))
+	for _, key := range keys {
+		if sKey, ok := key.(string); ok {
+			if sVal, ok := p.Hashtable.Get(key).(string); ok {
+				values[sKey] = sVal
+			}
+		}
+	}
+
+	return &Snapshot{values: values}
+}
+
+// Searches for the property with the specified key in this snapshot and
+// its defaults, recursively. Return "", false if the property is not found.
+func (s *Snapshot) GetProperty(key string) (string, bool) {
+	if val, exist := s.values[key]; exist {
+		return val, true
+	}
+	for _, d := range s.defaults {
+		if val, exist := d.GetProperty(key); exist {
+			return val, true
+		}
+	}
+
+	return "", false
+}
+
+// Returns the property value or defaultValue if the property is not found.
+func (s *Snapshot) GetPropertyByDefault(key, defaultValue string) string {
+	if val, exist := s.GetProperty(key); exist {
+		return val
+	}
+
+	return defaultValue
+}
+
+// Returns the number of properties, not counting the defaults.
+func (s *Snapshot) Size() int {
+	return len(s.values)
+}
+
+// Returns the keys of this snapshot, including the keys of
+// its defaults, in sorted order.
+func (s *Snapshot) StringPropertyNames() []string {
+	var m = s.ToMap()
+	var keys = make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Returns the effective properties of this snapshot,
+// including its defaults, as a new map.
+func (s *Snapshot) ToMap() map[string]string {
+	var m = make(map[string]string, len(s.values))
+	for i := len(s.defaults) - 1; i >= 0; i-- {
+		for key, val := range s.defaults[i].ToMap() {
+			m[key] = val
+		}
+	}
+	for key, val := range s.values {
+		m[key] = val
+	}
+
+	return m
+}
@@ -0,0 +1,105 @@
+package properties
+
+import (
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestProperties_Snapshot(t *testing.T) {
+	defaults := NewProperties()
+	defaults.SetProperty("a", "default")
+	defaults.SetProperty("b", "default")
+	p := NewPropertiesDefault(defaults)
+	p.SetProperty("a", "1")
+	p.Put("n", 1)
+
+	s := p.Snapshot()
+	p.SetProperty("a", "2")
+	p.SetProperty("c", "3")
+	defaults.SetProperty("b", "changed")
+
+	diff := cmp.Diff(s.ToMap(), map[string]string{"a": "1", "b": "default"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(s.StringPropertyNames(), []string{"a", "b"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff([]interface{}{s.Size(), s.GetPropertyByDefault("c", "none")}, []interface{}{1, "none"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestProperties_SetCopyOnWrite(t *testing.T) {
+	defaults := NewProperties()
+	defaults.SetProperty("b", "default")
+	p := NewPropertiesDefault(defaults)
+	p.SetProperty("a", "0")
+	p.SetCopyOnWrite(true)
+	if !p.CopyOnWrite() {
+		t.Fatal("copy-on-write not enabled")
+	}
+
+	s := p.Snapshot()
+	p.SetProperty("a", "1")
+	diff := cmp.Diff([]string{s.GetPropertyByDefault("a", ""), p.GetPropertyByDefault("a", "")}, []string{"0", "1"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	if err := p.Load(strings.NewReader("c = 3\n")); err != nil {
+		t.Fatal(err)
+	}
+	p.Remove("a")
+	diff = cmp.Diff(p.Snapshot().ToMap(), map[string]string{"b": "default", "c": "3"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	if err := p.Reload(strings.NewReader("d = 4\n")); err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff([]string{p.GetPropertyByDefault("c", "none"), p.GetPropertyByDefault("d", "")}, []string{"none", "4"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	p.SetCopyOnWrite(false)
+	p.SetProperty("e", "5")
+	diff = cmp.Diff([]interface{}{p.CopyOnWrite(), p.GetPropertyByDefault("e", "")}, []interface{}{false, "5"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestSnapshot_Concurrency(t *testing.T) {
+	p := NewProperties()
+	p.SetCopyOnWrite(true)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for n := 0; n < 500; n++ {
+			_ = p.Load(strings.NewReader(fmt.Sprintf("x = %d\ny = %d\n", n, n)))
+		}
+	}()
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 500; n++ {
+				s := p.Snapshot()
+				if x, y := s.GetPropertyByDefault("x", ""), s.GetPropertyByDefault("y", ""); x != y {
+					t.Errorf("inconsistent snapshot: x = %s, y = %s", x, y)
+					return
+				}
+				p.GetProperty("x")
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,269 @@
+package properties
+
+import (
+	"fmt"
+	"iter"
+	"math/rand"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Returns a negative number, zero, or a positive number as a is
+// less than, equal to, or greater than b.
+type Comparator func(a, b interface{}) int
+
+// Compares strings lexically by bytes. Keys of other types sort after
+// strings, by type name and then by their fmt representation.
+// A nil key sorts before every other key.
+func CompareKeys(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	sa, oka := a.(string)
+	sb, okb := b.(string)
+	switch {
+	case oka && okb:
+		return strings.Compare(sa, sb)
+	case oka:
+		return -1
+	case okb:
+		return 1
+	}
+	if c := strings.Compare(reflect.TypeOf(a).String(), reflect.TypeOf(b).String()); c != 0 {
+		return c
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// A Hashtable iterated in key order.
+type SortedHashtable interface {
+	Hashtable
+
+	// Calls fn for each key in the range [from, to) in order until fn
+	// returns false. A nil bound leaves the range open on that side.
+	// fn must not modify the hashtable.
+	Scan(from, to interface{}, fn func(key, value interface{}) bool)
+}
+
+// Returns the range of the string keys starting with prefix, for use with
+// SortedHashtable.Scan, e.g. "appender." .. "appender/".
+func PrefixRange(prefix string) (from, to interface{}) {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xff {
+			return prefix, prefix[:i] + string([]byte{prefix[i] + 1})
+		}
+	}
+
+	return prefix, nil
+}
+
+const (
+	skipMaxLevel = 32
+	skipP        = 4
+)
+
+type skipNode struct {
+	key   interface{}
+	value interface{}
+	next  []*skipNode
+}
+
+// sorted hashtable, a skip list.
+type sortedTable struct {
+	mutex   sync.RWMutex
+	compare Comparator
+	head    *skipNode
+	level   int
+	size    int
+	rand    *rand.Rand
+}
+
+// Creates an empty hashtable iterated in the order of compare,
+// CompareKeys if compare is nil.
+func NewSortedHashtable(compare Comparator) SortedHashtable {
+	if compare == nil {
+		compare = CompareKeys
+	}
+
+	return &sortedTable{
+		compare: compare,
+		head:    &skipNode{next: make([]*skipNode, skipMaxLevel)},
+		level:   1,
+		rand:    rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+// Creates an empty property list iterated in the order of compare,
+// CompareKeys if compare is nil.
+func NewSortedProperties(compare Comparator) *Properties {
+	properties := NewProperties()
+	properties.Hashtable = NewSortedHashtable(compare)
+
+	return properties
+}
+
+func (s *sortedTable) New() Hashtable {
+	return NewSortedHashtable(s.compare)
+}
+
+// Returns the last node before key on each level in update,
+// and the node of key or nil.
+func (s *sortedTable) find(key interface{}, update []*skipNode) *skipNode {
+	var x = s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && s.compare(x.next[i].key, key) < 0 {
+			x = x.next[i]
+		}
+		if update != nil {
+			update[i] = x
+		}
+	}
+	if x = x.next[0]; x != nil && s.compare(x.key, key) == 0 {
+		return x
+	}
+
+	return nil
+}
+
+func (s *sortedTable) randomLevel() int {
+	var level = 1
+	for level < skipMaxLevel && s.rand.Intn(skipP) == 0 {
+		level++
+	}
+
+	return level
+}
+
+func (s *sortedTable) Put(key, value interface{}) interface{} {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var update = make([]*skipNode, skipMaxLevel)
+	if x := s.find(key, update); x != nil {
+		old := x.value
+		x.value = value
+		return old
+	}
+
+	var level = s.randomLevel()
+	for i := s.level; i < level; i++ {
+		update[i] = s.head
+	}
+	if level > s.level {
+		s.level = level
+	}
+	var x = &skipNode{key: key, value: value, next: make([]*skipNode, level)}
+	for i := 0; i < level; i++ {
+		x.next[i] = update[i].next[i]
+		update[i].next[i] = x
+	}
+	s.size++
+
+	return nil
+}
+
+func (s *sortedTable) Get(key interface{}) interface{} {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if x := s.find(key, nil); x != nil {
+		return x.value
+	}
+
+	return nil
+}
+
+func (s *sortedTable) Remove(key interface{}) interface{} {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var update = make([]*skipNode, skipMaxLevel)
+	var x = s.find(key, update)
+	if x == nil {
+		return nil
+	}
+	for i := 0; i < len(x.next); i++ {
+		update[i].next[i] = x.next[i]
+	}
+	for s.level > 1 && s.head.next[s.level-1] == nil {
+		s.level--
+	}
+	s.size--
+
+	return x.value
+}
+
+func (s *sortedTable) Clear() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.head = &skipNode{next: make([]*skipNode, skipMaxLevel)}
+	s.level = 1
+	s.size = 0
+}
+
+func (s *sortedTable) ContainsKey(key interface{}) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.find(key, nil) != nil
+}
+
+func (s *sortedTable) Size() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.size
+}
+
+func (s *sortedTable) Keys() []interface{} {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var keys = make([]interface{}, 0, s.size)
+	for x := s.head.next[0]; x != nil; x = x.next[0] {
+		keys = append(keys, x.key)
+	}
+
+	return keys
+}
+
+func (s *sortedTable) Range(fn func(key, value interface{}) bool) {
+	s.Scan(nil, nil, fn)
+}
+
+func (s *sortedTable) All() iter.Seq2[interface{}, interface{}] {
+	return s.Range
+}
+
+func (s *sortedTable) Scan(from, to interface{}, fn func(key, value interface{}) bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var x = s.head.next[0]
+	if from != nil {
+		var y = s.head
+		for i := s.level - 1; i >= 0; i-- {
+			for y.next[i] != nil && s.compare(y.next[i].key, from) < 0 {
+				y = y.next[i]
+			}
+		}
+		x = y.next[0]
+	}
+	for ; x != nil; x = x.next[0] {
+		if to != nil && s.compare(x.key, to) >= 0 {
+			return
+		}
+		if !fn(x.key, x.value) {
+			return
+		}
+	}
+}
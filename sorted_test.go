@@ -0,0 +1,139 @@
+package properties
+
+import (
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestNewSortedHashtable(t *testing.T) {
+	table := NewSortedHashtable(nil)
+	mapper := map[string]int{}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		key := fmt.Sprintf("k%03d", r.Intn(500))
+		if r.Intn(3) == 0 {
+			table.Remove(key)
+			delete(mapper, key)
+			continue
+		}
+		old, exist := mapper[key]
+		var want interface{}
+		if exist {
+			want = old
+		}
+		diff := cmp.Diff(table.Put(key, i), want)
+		if diff != "" {
+			t.Fatal(diff)
+		}
+		mapper[key] = i
+	}
+
+	var keys []interface{}
+	var sorted []string
+	for key := range mapper {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	for _, key := range sorted {
+		keys = append(keys, key)
+		diff := cmp.Diff(table.Get(key), mapper[key])
+		if diff != "" {
+			t.Fatal(key, diff)
+		}
+	}
+	diff := cmp.Diff(table.Keys(), keys)
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(table.Size(), len(mapper))
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	reverse := NewSortedHashtable(func(a, b interface{}) int { return -CompareKeys(a, b) })
+	reverse.Put("a", 1)
+	reverse.Put(2, 2)
+	reverse.Put("b", 3)
+	reverse.Put(1, 4)
+	diff = cmp.Diff(reverse.New().Size(), 0)
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(reverse.Keys(), []interface{}{2, 1, "b", "a"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	diff = cmp.Diff([]int{CompareKeys(nil, nil), CompareKeys(nil, ""), CompareKeys("", nil), CompareKeys(1, nil)}, []int{0, -1, 1, 1})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestSortedHashtable_Scan(t *testing.T) {
+	table := NewSortedHashtable(nil)
+	for _, key := range []string{"appender", "appender.console.type", "appender.rolling.name", "appender/", "appender.console.name", "logger.name", "appender-x"} {
+		table.Put(key, strings.ToUpper(key))
+	}
+
+	var keys []interface{}
+	from, to := PrefixRange("appender.")
+	diff := cmp.Diff([]interface{}{from, to}, []interface{}{"appender.", "appender/"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	table.Scan(from, to, func(key, value interface{}) bool {
+		keys = append(keys, key)
+		return true
+	})
+	diff = cmp.Diff(keys, []interface{}{"appender.console.name", "appender.console.type", "appender.rolling.name"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	keys = nil
+	table.Scan(nil, nil, func(key, value interface{}) bool {
+		keys = append(keys, key)
+		return len(keys) < 2
+	})
+	diff = cmp.Diff(keys, []interface{}{"appender", "appender-x"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	from, to = PrefixRange("\xff\xff")
+	diff = cmp.Diff([]interface{}{from, to}, []interface{}{"\xff\xff", nil})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	from, to = PrefixRange("a\xff")
+	diff = cmp.Diff(to, interface{}("b"))
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestNewSortedProperties(t *testing.T) {
+	p := NewSortedProperties(nil)
+	p.SetProperty("version", "1.9.2")
+	p.SetProperty("title", "properties")
+	p.SetProperty("language", "golang")
+
+	var buf strings.Builder
+	if err := p.Store(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	diff := cmp.Diff(lines[1:], []string{"language = golang", "title = properties", "version = 1.9.2"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(p.StringPropertyNames(), []string{"language", "title", "version"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
@@ -0,0 +1,411 @@
+package properties
+
+import (
+	"fmt"
+	"io"
+	"iter"
+	"sync"
+)
+
+// A type safe hashtable.
+type Table[K comparable, V any] interface {
+	New() Table[K, V]
+	Put(key K, value V) (old V, exist bool)
+	Get(key K) (value V, exist bool)
+	Remove(key K) (old V, exist bool)
+	Clear()
+	Size() int
+	Keys() []K
+
+	// Calls fn for each key and value in iteration order until fn returns
+	// false, without allocating. fn must not call any method of the
+	// table, which may hold its lock during the iteration.
+	Range(fn func(key K, value V) bool)
+	// Returns an iterator over the keys and values, see Range.
+	All() iter.Seq2[K, V]
+}
+
+type mapTable[K comparable, V any] struct {
+	mutex  sync.RWMutex
+	mapper map[K]V
+}
+
+// Creates an empty table iterated in random order.
+func NewTable[K comparable, V any]() Table[K, V] {
+	return &mapTable[K, V]{
+		mapper: map[K]V{},
+	}
+}
+
+func (t *mapTable[K, V]) New() Table[K, V] {
+	return NewTable[K, V]()
+}
+
+func (t *mapTable[K, V]) Put(key K, value V) (V, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	old, exist := t.mapper[key]
+	t.mapper[key] = value
+
+	return old, exist
+}
+
+func (t *mapTable[K, V]) Get(key K) (V, bool) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	value, exist := t.mapper[key]
+
+	return value, exist
+}
+
+func (t *mapTable[K, V]) Remove(key K) (V, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	old, exist := t.mapper[key]
+	delete(t.mapper, key)
+
+	return old, exist
+}
+
+func (t *mapTable[K, V]) Clear() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.mapper = map[K]V{}
+}
+
+func (t *mapTable[K, V]) Size() int {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	return len(t.mapper)
+}
+
+func (t *mapTable[K, V]) Keys() []K {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	var keys = make([]K, 0, len(t.mapper))
+	for key := range t.mapper {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func (t *mapTable[K, V]) Range(fn func(key K, value V) bool) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	for key, value := range t.mapper {
+		if !fn(key, value) {
+			return
+		}
+	}
+}
+
+func (t *mapTable[K, V]) All() iter.Seq2[K, V] {
+	return t.Range
+}
+
+// Adapts a Table to the legacy Hashtable interface. Get and Remove of a key
+// that is not a K find nothing; Put of a key that is not a K or a value that
+// is not a V panics, as the table cannot hold it.
+func ToHashtable[K comparable, V any](t Table[K, V]) Hashtable {
+	return &legacyTable[K, V]{table: t}
+}
+
+type legacyTable[K comparable, V any] struct {
+	table Table[K, V]
+}
+
+func (l *legacyTable[K, V]) New() Hashtable {
+	return ToHashtable(l.table.New())
+}
+
+func (l *legacyTable[K, V]) Put(key, value interface{}) interface{} {
+	k, ok := key.(K)
+	if !ok {
+		panic(fmt.Sprintf("properties: key %#v is not of type %T", key, *new(K)))
+	}
+	v, ok := value.(V)
+	if !ok {
+		panic(fmt.Sprintf("properties: value %#v of key %#v is not of type %T", value, key, *new(V)))
+	}
+	if old, exist := l.table.Put(k, v); exist {
+		return old
+	}
+
+	return nil
+}
+
+func (l *legacyTable[K, V]) Get(key interface{}) interface{} {
+	if k, ok := key.(K); ok {
+		if v, exist := l.table.Get(k); exist {
+			return v
+		}
+	}
+
+	return nil
+}
+
+func (l *legacyTable[K, V]) Remove(key interface{}) interface{} {
+	if k, ok := key.(K); ok {
+		if old, exist := l.table.Remove(k); exist {
+			return old
+		}
+	}
+
+	return nil
+}
+
+func (l *legacyTable[K, V]) Clear() {
+	l.table.Clear()
+}
+
+func (l *legacyTable[K, V]) ContainsKey(key interface{}) bool {
+	if k, ok := key.(K); ok {
+		_, exist := l.table.Get(k)
+		return exist
+	}
+
+	return false
+}
+
+func (l *legacyTable[K, V]) Size() int {
+	return l.table.Size()
+}
+
+func (l *legacyTable[K, V]) Keys() []interface{} {
+	var keys = l.table.Keys()
+	var set = make([]interface{}, len(keys))
+	for i, key := range keys {
+		set[i] = key
+	}
+
+	return set
+}
+
+func (l *legacyTable[K, V]) Range(fn func(key, value interface{}) bool) {
+	l.table.Range(func(key K, value V) bool {
+		return fn(key, value)
+	})
+}
+
+func (l *legacyTable[K, V]) All() iter.Seq2[interface{}, interface{}] {
+	return l.Range
+}
+
+// Adapts a legacy Hashtable to a Table. Entries whose key is not a K or
+// whose value is not a V are omitted.
+func FromHashtable[K comparable, V any](h Hashtable) Table[K, V] {
+	return &typedTable[K, V]{hash: h}
+}
+
+type typedTable[K comparable, V any] struct {
+	hash Hashtable
+}
+
+func (t *typedTable[K, V]) New() Table[K, V] {
+	return FromHashtable[K, V](t.hash.New())
+}
+
+func (t *typedTable[K, V]) Put(key K, value V) (V, bool) {
+	old, exist := t.hash.Put(key, value).(V)
+
+	return old, exist
+}
+
+func (t *typedTable[K, V]) Get(key K) (V, bool) {
+	value, exist := t.hash.Get(key).(V)
+
+	return value, exist
+}
+
+func (t *typedTable[K, V]) Remove(key K) (V, bool) {
+	old, exist := t.hash.Remove(key).(V)
+
+	return old, exist
+}
+
+func (t *typedTable[K, V]) Clear() {
+	t.hash.Clear()
+}
+
+func (t *typedTable[K, V]) Size() int {
+	return len(t.Keys())
+}
+
+func (t *typedTable[K, V]) Keys() []K {
+	var keys []K
+	for _, key := range t.hash.Keys() {
+		if k, ok := key.(K); ok {
+			if _, ok = t.hash.Get(key).(V); ok {
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	return keys
+}
+
+func (t *typedTable[K, V]) Range(fn func(key K, value V) bool) {
+	t.hash.Range(func(key, value interface{}) bool {
+		k, ok := key.(K)
+		if !ok {
+			return true
+		}
+		v, ok := value.(V)
+		if !ok {
+			return true
+		}
+		return fn(k, v)
+	})
+}
+
+func (t *typedTable[K, V]) All() iter.Seq2[K, V] {
+	return t.Range
+}
+
+// A property list whose keys and values are strings, enforced at compile
+// time. It shares the parsing and formatting of Properties.
+type StringProperties struct {
+	props *Properties
+}
+
+// Creates an empty string property list with no default values.
+func NewStringProperties() *StringProperties {
+	return NewStringPropertiesTable(NewTable[string, string](), nil)
+}
+
+// Creates an empty string property list with the specified defaults.
+func NewStringPropertiesDefault(defaults *StringProperties) *StringProperties {
+	return NewStringPropertiesTable(NewTable[string, string](), defaults)
+}
+
+// Creates a string property list stored in the specified table,
+// with the specified defaults, which may be nil.
+func NewStringPropertiesTable(table Table[string, string], defaults *StringProperties) *StringProperties {
+	var props = NewProperties()
+	props.Hashtable = ToHashtable(table)
+	if defaults != nil {
+		props.setDefaults([]*Properties{defaults.props})
+	}
+
+	return &StringProperties{
+		props: props,
+	}
+}
+
+// Sets the property and returns the previous value.
+func (s *StringProperties) SetProperty(key, value string) (old string, exist bool) {
+	return stringResult(s.props.SetProperty(key, value))
+}
+
+// Searches for the property in this property list and the default
+// property list, recursively.
+func (s *StringProperties) GetProperty(key string) (string, bool) {
+	return s.props.GetProperty(key)
+}
+
+// Returns the property value or defaultValue if the property is not found.
+func (s *StringProperties) GetPropertyByDefault(key, defaultValue string) string {
+	return s.props.GetPropertyByDefault(key, defaultValue)
+}
+
+// Removes the property from this property list and returns its value,
+// the default property list is not affected.
+func (s *StringProperties) Remove(key string) (old string, exist bool) {
+	return stringResult(s.props.Remove(key))
+}
+
+// Returns the previous value of a write of the underlying property list,
+// which holds only strings. Panics if the value is neither nil nor a string.
+func stringResult(v interface{}) (string, bool) {
+	if v == nil {
+		return "", false
+	}
+	if s, ok := v.(string); ok {
+		return s, true
+	}
+
+	panic(fmt.Sprintf("properties: non-string value %T in a string property list", v))
+}
+
+// Returns the number of properties in this property list,
+// not counting the default property list.
+func (s *StringProperties) Size() int {
+	return s.props.Size()
+}
+
+// Returns the keys of this property list,
+// not including the default property list.
+func (s *StringProperties) Keys() []string {
+	var keys = s.props.Keys()
+	var set = make([]string, len(keys))
+	for i, key := range keys {
+		set[i] = key.(string)
+	}
+
+	return set
+}
+
+// Returns the keys of this property list,
+// including the default property list.
+func (s *StringProperties) StringPropertyNames() []string {
+	return s.props.StringPropertyNames()
+}
+
+// Properties to map, not including the default property list.
+func (s *StringProperties) ToMap() map[string]string {
+	var m = make(map[string]string)
+	for key, value := range s.props.ToMap() {
+		m[key.(string)] = value.(string)
+	}
+
+	return m
+}
+
+// See Properties.Range.
+func (s *StringProperties) Range(fn func(key, value string) bool) {
+	s.props.Range(fn)
+}
+
+// See Properties.All.
+func (s *StringProperties) All() iter.Seq2[string, string] {
+	return s.props.All()
+}
+
+// See Properties.Load.
+func (s *StringProperties) Load(reader io.Reader) error {
+	return s.props.Load(reader)
+}
+
+// See Properties.Store.
+func (s *StringProperties) Store(writer io.Writer, comments []byte) error {
+	return s.props.Store(writer, comments)
+}
+
+// See Properties.LoadFromXML.
+func (s *StringProperties) LoadFromXML(reader io.Reader) error {
+	return s.props.LoadFromXML(reader)
+}
+
+// See Properties.StoreToXML.
+func (s *StringProperties) StoreToXML(writer io.Writer, comments []byte) error {
+	return s.props.StoreToXML(writer, comments)
+}
+
+// See Properties.List.
+func (s *StringProperties) List(out io.Writer) {
+	s.props.List(out)
+}
+
+// Returns the legacy view of this property list, sharing its storage.
+// Putting a key or value that is not a string through the view panics.
+func (s *StringProperties) Properties() *Properties {
+	return s.props
+}
@@ -0,0 +1,156 @@
+package properties
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestNewTable(t *testing.T) {
+	table := NewTable[string, int]()
+	table.Put("k1", 1)
+	old, exist := table.Put("k1", 2)
+	diff := cmp.Diff([]interface{}{old, exist}, []interface{}{1, true})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	table.Put("k2", 3)
+	value, exist := table.Get("k1")
+	diff = cmp.Diff([]interface{}{value, exist}, []interface{}{2, true})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	table.Remove("k1")
+	if _, exist = table.Get("k1"); exist {
+		t.Fatal("removed key found")
+	}
+	diff = cmp.Diff(table.Keys(), []string{"k2"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(table.New().Size(), 0)
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestToHashtable(t *testing.T) {
+	h := ToHashtable(NewTable[string, string]())
+	diff := cmp.Diff(h.Put("k1", "v1"), nil)
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(h.Put("k1", "v2"), interface{}("v1"))
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff([]interface{}{h.Get("k1"), h.Get(1), h.Size()}, []interface{}{"v2", nil, 1})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	h.Remove(1)
+	h.Remove("k1")
+	diff = cmp.Diff(h.Keys(), []interface{}{})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("non-string value accepted")
+		}
+	}()
+	h.Put("port", 8080)
+}
+
+func TestFromHashtable(t *testing.T) {
+	h := NewHashtable2()
+	h.Put("k1", "v1")
+	h.Put("k2", 2)
+	h.Put(3, "v3")
+	h.Put("k4", "v4")
+
+	table := FromHashtable[string, string](h)
+	diff := cmp.Diff(table.Keys(), []string{"k1", "k4"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	if _, exist := table.Get("k2"); exist {
+		t.Fatal("non-string value found")
+	}
+	table.Put("k5", "v5")
+	diff = cmp.Diff([]interface{}{table.Size(), h.Get("k5")}, []interface{}{3, "v5"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestStringProperties(t *testing.T) {
+	defaults := NewStringProperties()
+	defaults.SetProperty("language", "golang")
+
+	p := NewStringPropertiesDefault(defaults)
+	if err := p.Load(strings.NewReader("title = properties\nversion = 1.9.2\n")); err != nil {
+		t.Fatal(err)
+	}
+	old, exist := p.SetProperty("version", "1.10")
+	diff := cmp.Diff([]interface{}{old, exist}, []interface{}{"1.9.2", true})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	diff = cmp.Diff(p.ToMap(), map[string]string{"title": "properties", "version": "1.10"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	names := p.StringPropertyNames()
+	sort.Strings(names)
+	diff = cmp.Diff(names, []string{"language", "title", "version"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	stored := NewStringProperties()
+	stored.SetProperty("title", "properties")
+	stored.SetProperty("version", "1.10")
+	var buf strings.Builder
+	if err := stored.StoreToXML(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewStringProperties()
+	if err := loaded.LoadFromXML(strings.NewReader(buf.String())); err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff(loaded.ToMap(), p.ToMap())
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	loaded.Remove("title")
+	keys := loaded.Keys()
+	diff = cmp.Diff(keys, []string{"version"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestStringProperties_Frozen(t *testing.T) {
+	p := NewStringProperties()
+	p.SetProperty("version", "1.10")
+	p.Properties().Freeze()
+
+	if r := mustPanic(func() { p.SetProperty("version", "1.11") }); r != ErrFrozen {
+		t.Fatal(r)
+	}
+	if r := mustPanic(func() { p.Remove("version") }); r != ErrFrozen {
+		t.Fatal(r)
+	}
+	value, _ := p.GetProperty("version")
+	diff := cmp.Diff(value, "1.10")
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	if mustPanic(func() { stringResult(8080) }) == nil {
+		t.Fatal("non-string value accepted")
+	}
+}
@@ -0,0 +1,109 @@
+package properties
+
+// A batch of changes to a property list, applied atomically and
+// all-or-nothing by Properties.Update.
+type Tx struct {
+	props   *Properties
+	ops     []txOp
+	pending map[string]txOp
+
+	// The version of props when the transaction started,
+	// and whether the transaction read it.
+	version uint64
+	read    bool
+}
+
+type txOp struct {
+	key    string
+	value  string
+	remove bool
+}
+
+// Sets the property when the transaction commits.
+func (tx *Tx) Set(key, value string) {
+	var op = txOp{key: key, value: value}
+	tx.ops = append(tx.ops, op)
+	tx.pending[key] = op
+}
+
+// Removes the property when the transaction commits,
+// the default property list is not affected.
+func (tx *Tx) Remove(key string) {
+	var op = txOp{key: key, remove: true}
+	tx.ops = append(tx.ops, op)
+	tx.pending[key] = op
+}
+
+// Searches for the property like Properties.GetProperty,
+// seeing the changes made in this transaction.
+func (tx *Tx) Get(key string) (string, bool) {
+	if op, ok := tx.pending[key]; ok {
+		if op.remove {
+			return tx.props.getDefault(key)
+		}
+		return op.value, true
+	}
+
+	tx.read = true
+	return tx.props.GetProperty(key)
+}
+
+// Calls fn with a new transaction and, if fn returns nil, applies all of
+// its changes atomically: readers observe either none or all of them.
+// If fn returns an error nothing is changed and the error is returned.
+// If fn read the property list through the transaction and the property
+// list changed before the commit, the changes are discarded and fn is
+// called again with a new transaction, so concurrent read-modify-write
+// updates are never lost. fn may therefore be called more than once.
+// Changes of the default property list are not detected.
+// fn must not modify the property list other than through the transaction.
+func (p *Properties) Update(fn func(tx *Tx) error) error {
+	return p.UpdateBy("", fn)
+}
+
+// Like Update, recording actor as the author of the changes in history
+// mode, see SetHistory.
+func (p *Properties) UpdateBy(actor string, fn func(tx *Tx) error) error {
+	for {
+		var tx = &Tx{
+			props:   p,
+			pending: make(map[string]txOp),
+			version: p.Version(),
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if len(tx.ops) == 0 {
+			return nil
+		}
+		if done, err := p.commitTx(actor, tx); done || err != nil {
+			return err
+		}
+	}
+}
+
+// Applies the changes of tx, unless it read a version of the property list
+// that is out of date, reporting whether it did.
+func (p *Properties) commitTx(actor string, tx *Tx) (bool, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err := p.writableLocked(); err != nil {
+		return false, err
+	}
+	if tx.read && tx.version != p.version {
+		return false, nil
+	}
+
+	var changes []Change
+	for _, op := range tx.ops {
+		if op.remove {
+			changes = appendChange(changes, op.key, p.Hashtable.Remove(op.key), nil)
+		} else {
+			changes = appendChange(changes, op.key, p.Hashtable.Put(op.key, op.value), op.value)
+		}
+	}
+	p.commitByLocked(actor, coalesce(changes))
+
+	return true, nil
+}
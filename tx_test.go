@@ -0,0 +1,117 @@
+package properties
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"sync"
+	"testing"
+)
+
+func TestProperties_Update(t *testing.T) {
+	defaults := NewProperties()
+	defaults.SetProperty("db.port", "5432")
+	p := NewPropertiesDefault(defaults)
+	p.SetProperty("db.host", "old")
+	p.SetProperty("db.port", "6543")
+
+	err := p.Update(func(tx *Tx) error {
+		tx.Set("db.host", "new")
+		tx.Remove("db.port")
+		tx.Set("db.user", "admin")
+		host, _ := tx.Get("db.host")
+		port, _ := tx.Get("db.port")
+		diff := cmp.Diff([]string{host, port}, []string{"new", "5432"})
+		if diff != "" {
+			t.Fatal(diff)
+		}
+		diff = cmp.Diff(p.GetPropertyByDefault("db.host", ""), "old")
+		if diff != "" {
+			t.Fatal(diff)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := cmp.Diff(p.ToMap(), map[interface{}]interface{}{"db.host": "new", "db.user": "admin"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	fail := errors.New("fail")
+	err = p.Update(func(tx *Tx) error {
+		tx.Set("db.host", "failed")
+		return fail
+	})
+	diff = cmp.Diff([]interface{}{err == fail, p.GetPropertyByDefault("db.host", "")}, []interface{}{true, "new"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestProperties_UpdateConcurrency(t *testing.T) {
+	p := NewProperties()
+	p.SetCopyOnWrite(true)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for n := 0; n < 500; n++ {
+			_ = p.Update(func(tx *Tx) error {
+				tx.Set("db.host", fmt.Sprint("host", n))
+				tx.Set("db.port", fmt.Sprint("port", n))
+				return nil
+			})
+		}
+	}()
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 500; n++ {
+				m := p.Snapshot().ToMap()
+				if len(m["db.host"]) > 4 && m["db.host"][4:] != m["db.port"][4:] {
+					t.Errorf("inconsistent update: %v", m)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestProperties_UpdateLostUpdate(t *testing.T) {
+	p := NewProperties()
+	p.SetProperty("counter", "0")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 100; n++ {
+				err := p.Update(func(tx *Tx) error {
+					v, _ := tx.Get("counter")
+					var c int
+					if _, err := fmt.Sscan(v, &c); err != nil {
+						return err
+					}
+					tx.Set("counter", fmt.Sprint(c+1))
+					return nil
+				})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	diff := cmp.Diff(p.GetPropertyByDefault("counter", ""), "800")
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
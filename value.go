@@ -0,0 +1,131 @@
+package properties
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Handling of keys and values that are not strings when a property list
+// is written by Store, List or StoreToXML. A Hashtable accepts any key
+// and value, but the text and XML formats hold strings only.
+type ValuePolicy int
+
+const (
+	// Converts keys and values with encoding.TextMarshaler, fmt.Stringer,
+	// or strconv for booleans and numbers, []byte is taken as is.
+	// Anything else is rejected with a *ValueError.
+	ConvertValues ValuePolicy = iota
+	// Rejects any key or value that is not a string with a *ValueError.
+	RejectValues
+	// Omits the entries whose key or value is not a string,
+	// like StringPropertyNames.
+	SkipValues
+)
+
+// Reports a key or value that cannot be written as a string.
+type ValueError struct {
+	Key   interface{}
+	Value interface{}
+
+	// The error of encoding.TextMarshaler, if any.
+	Err error
+}
+
+func (e *ValueError) Error() string {
+	var msg = fmt.Sprintf("properties: key %#v with value of type %T cannot be written as a string", e.Key, e.Value)
+	if _, ok := e.Key.(string); !ok {
+		msg = fmt.Sprintf("properties: key %#v of type %T cannot be written as a string", e.Key, e.Key)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+
+	return msg
+}
+
+func (e *ValueError) Unwrap() error {
+	return e.Err
+}
+
+// Returns the key and value as strings according to the policy,
+// ok is false if the entry is to be omitted.
+func (policy ValuePolicy) strings(key, value interface{}) (sKey, sVal string, ok bool, err error) {
+	if sKey, ok = key.(string); ok {
+		sVal, ok = value.(string)
+	}
+	if ok {
+		return sKey, sVal, true, nil
+	}
+
+	switch policy {
+	case SkipValues:
+		return "", "", false, nil
+	case RejectValues:
+		if _, isString := key.(string); !isString {
+			return "", "", false, &ValueError{Key: key, Value: key}
+		}
+		return "", "", false, &ValueError{Key: key, Value: value}
+	}
+
+	if sKey, err = toString(key); err != nil {
+		return "", "", false, &ValueError{Key: key, Value: key, Err: err}
+	}
+	if sVal, err = toString(value); err != nil {
+		return "", "", false, &ValueError{Key: key, Value: value, Err: err}
+	}
+
+	return sKey, sVal, true, nil
+}
+
+// errNotConvertible is returned by toString for values without a string form.
+var errNotConvertible = errors.New("no string conversion")
+
+// Converts a value to its string form.
+func toString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		if err != nil {
+			return "", err
+		}
+		return string(text), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case uintptr:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	}
+
+	return "", errNotConvertible
+}
@@ -0,0 +1,142 @@
+package properties
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+type failText struct{}
+
+func (failText) MarshalText() ([]byte, error) {
+	return nil, errors.New("fail")
+}
+
+func TestValuePolicy(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		text  string
+	}{
+		{"v", "v"},
+		{8080, "8080"},
+		{uint8(7), "7"},
+		{true, "true"},
+		{1.5, "1.5"},
+		{[]byte("raw"), "raw"},
+		{time.Second, "1s"},
+		{net.IPv4(127, 0, 0, 1), "127.0.0.1"},
+	}
+	for _, test := range tests {
+		_, text, ok, err := ConvertValues.strings("key", test.value)
+		if err != nil || !ok {
+			t.Fatal(test.value, ok, err)
+		}
+		diff := cmp.Diff(text, test.text)
+		if diff != "" {
+			t.Fatal(diff)
+		}
+	}
+
+	var e *ValueError
+	_, _, _, err := ConvertValues.strings("key", struct{}{})
+	if !errors.As(err, &e) || e.Key != "key" {
+		t.Fatal("unconvertible value accepted:", err)
+	}
+	_, _, _, err = ConvertValues.strings("key", failText{})
+	if !errors.As(err, &e) || e.Err == nil || e.Err.Error() != "fail" {
+		t.Fatal("marshaler error lost:", err)
+	}
+	_, _, _, err = RejectValues.strings(1, "v")
+	if !errors.As(err, &e) || e.Key != 1 {
+		t.Fatal("non-string key accepted:", err)
+	}
+	_, _, ok, err := SkipValues.strings("key", 1)
+	if ok || err != nil {
+		t.Fatal("non-string value not skipped:", ok, err)
+	}
+}
+
+func TestProperties_StoreByPolicy(t *testing.T) {
+	p := NewSortedProperties(nil)
+	p.SetProperty("host", "localhost")
+	p.Put("port", 8080)
+	p.Put("timeout", 3*time.Second)
+
+	var buf strings.Builder
+	if err := p.Store(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	diff := cmp.Diff(lines[1:], []string{"host = localhost", "port = 8080", "timeout = 3s"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	buf.Reset()
+	if err := p.StoreByPolicy(&buf, nil, SkipValues); err != nil {
+		t.Fatal(err)
+	}
+	lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	diff = cmp.Diff(lines[1:], []string{"host = localhost"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	err := p.StoreByPolicy(&buf, nil, RejectValues)
+	if err == nil || !strings.Contains(err.Error(), `"port"`) {
+		t.Fatal("offending key not reported:", err)
+	}
+	if err = p.ListByPolicy(&buf, RejectValues); err == nil {
+		t.Fatal("offending key not reported")
+	}
+
+	buf.Reset()
+	if err = p.ListByPolicy(&buf, ConvertValues); err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff(buf.String(), "-- listing properties --\nhost = localhost\nport = 8080\ntimeout = 3s\n")
+	if diff != "" {
+		t.Fatal(diff)
+	}
+
+	buf.Reset()
+	if err = p.StoreToXML(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewSortedProperties(nil)
+	if err = loaded.LoadFromXML(strings.NewReader(buf.String())); err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff(loaded.ToMap(), map[interface{}]interface{}{"host": "localhost", "port": "8080", "timeout": "3s"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+	if err = p.StoreToXMLByPolicy(&buf, nil, "UTF-8", RejectValues); err == nil {
+		t.Fatal("offending key not reported")
+	}
+}
+
+func TestProperties_StoreToXMLDefaults(t *testing.T) {
+	defaults := NewProperties()
+	defaults.SetProperty("a", "1")
+	defaults.SetProperty("b", "2")
+	p := NewPropertiesDefault(defaults)
+	p.SetProperty("b", "3")
+	p.SetProperty("c", "4")
+
+	var buf strings.Builder
+	if err := p.StoreToXML(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewProperties()
+	if err := loaded.LoadFromXML(strings.NewReader(buf.String())); err != nil {
+		t.Fatal(err)
+	}
+	diff := cmp.Diff(loaded.ToMap(), map[interface{}]interface{}{"a": "1", "b": "3", "c": "4"})
+	if diff != "" {
+		t.Fatal(diff)
+	}
+}
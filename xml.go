@@ -13,19 +13,19 @@ const Header = `<!DOCTYPE properties SYSTEM "http://github.com/zooyer/properties
 
 // interface
 type XmlSupport interface {
-	load(props *Properties, in io.Reader) error
-	store(props *Properties, out io.Writer, comment []byte, encoding string) error
+	load(table Hashtable, in io.Reader) error
+	store(props *Properties, out io.Writer, comment []byte, encoding string, policy ValuePolicy) error
 }
 
 // global single SmlSupport object
 var PROVIDER = newXmlSupport()
 
-func load(props *Properties, in io.Reader) error {
-	return PROVIDER.load(props, in)
+func load(table Hashtable, in io.Reader) error {
+	return PROVIDER.load(table, in)
 }
 
-func save(props *Properties, out io.Writer, comment []byte, encoding string) error {
-	return PROVIDER.store(props, out, comment, encoding)
+func save(props *Properties, out io.Writer, comment []byte, encoding string, policy ValuePolicy) error {
+	return PROVIDER.store(props, out, comment, encoding, policy)
 }
 
 // XmlSupport implement
@@ -35,7 +35,8 @@ func newXmlSupport() XmlSupport {
 	return new(xmlSupport)
 }
 
-func (x *xmlSupport) load(props *Properties, in io.Reader) error {
+// Puts the entries of the XML document into table.
+func (x *xmlSupport) load(table Hashtable, in io.Reader) error {
 	data, err := ioutil.ReadAll(in)
 	if err != nil {
 		return err
@@ -44,16 +45,11 @@ func (x *xmlSupport) load(props *Properties, in io.Reader) error {
 	if err = xml.Unmarshal(data, &m); err != nil {
 		return err
 	}
-	p := props.New()
-	if err = x.toProperties(p, &m); err != nil {
-		return err
-	}
-	props.Hashtable = p.Hashtable
 
-	return nil
+	return x.toProperties(table, &m)
 }
 
-func (x *xmlSupport) store(props *Properties, out io.Writer, comment []byte, encoding string) error {
+func (x *xmlSupport) store(props *Properties, out io.Writer, comment []byte, encoding string, policy ValuePolicy) error {
 	if props == nil {
 		return errors.New("props(Properties) is <nil>")
 	}
@@ -65,7 +61,7 @@ func (x *xmlSupport) store(props *Properties, out io.Writer, comment []byte, enc
 	default:
 		return errors.New("not support encoding <" + encoding + ">")
 	}
-	xp, err := x.toXML(props)
+	xp, err := x.toXML(props, policy)
 	if err != nil {
 		return err
 	}
@@ -83,35 +79,45 @@ func (x *xmlSupport) store(props *Properties, out io.Writer, comment []byte, enc
 	return nil
 }
 
-func (x *xmlSupport) toProperties(props *Properties, xp *XMLProperties) error {
-	if props == nil {
-		return errors.New("props(Properties) is <nil>")
+func (x *xmlSupport) toProperties(table Hashtable, xp *XMLProperties) error {
+	if table == nil {
+		return errors.New("table(Hashtable) is <nil>")
 	}
 	if xp == nil {
 		return errors.New("xp(XMLProperties) is <nil>")
 	}
 
 	for _, v := range xp.Entry {
-		props.Put(v.Key, v.CDATA)
+		table.Put(v.Key, v.CDATA)
 	}
 
 	return nil
 }
 
-func (x *xmlSupport) toXML(props *Properties) (*XMLProperties, error) {
+// Converts the properties, including the default property list, handling
+// keys and values that are not strings according to the policy.
+func (x *xmlSupport) toXML(props *Properties, policy ValuePolicy) (*XMLProperties, error) {
 	if props == nil {
 		return nil, errors.New("props(Properties) is <nil>")
 	}
+	var h = props.newHashtable()
+	props.enumerate(h)
+
 	var xp = new(XMLProperties)
-	xp.Entry = make([]XMLReaderEntry, props.Size())
-	for i, key := range props.StringPropertyNames() {
-		val, exist := props.GetProperty(key)
-		if exist {
-			xp.Entry[i] = XMLReaderEntry{
-				Key:   key,
-				CDATA: val,
-			}
+	var keys = h.Keys()
+	xp.Entry = make([]XMLReaderEntry, 0, len(keys))
+	for _, key := range keys {
+		sKey, sVal, ok, err := policy.strings(key, h.Get(key))
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
 		}
+		xp.Entry = append(xp.Entry, XMLReaderEntry{
+			Key:   sKey,
+			CDATA: sVal,
+		})
 	}
 
 	return xp, nil